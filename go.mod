@@ -3,6 +3,6 @@ module github.com/Alexeychuk/Gator
 go 1.24.3
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/lib/pq v1.10.9 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.10.9
 )
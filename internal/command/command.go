@@ -4,9 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/Alexeychuk/Gator/internal/config"
@@ -19,8 +23,9 @@ import (
 )
 
 type State struct {
-	Db  *database.Queries
-	Cfg *config.Config
+	Db   *database.Queries
+	Conn *sql.DB
+	Cfg  *config.Config
 }
 
 type Command struct {
@@ -44,13 +49,7 @@ func (c *Commands) Run(s *State, cmd Command) error {
 		return errors.New("no command found")
 	}
 
-	err := command(s, cmd)
-
-	if err == nil {
-		return err
-	}
-
-	return nil
+	return command(s, cmd)
 }
 
 func (c *Commands) Register(name string, f func(*State, Command) error) {
@@ -148,81 +147,176 @@ func HandlerGetUsers(s *State, cmd Command) error {
 	return nil
 }
 
-func scrapeFeeds(s *State) error {
-	feed_to_fetch, err := s.Db.GetNextFeedToFetch(context.Background())
-	if err != nil {
-		return err
-	}
+const (
+	defaultAggWorkers   = 8
+	defaultAggPerHost   = 2
+	defaultAggBatchSize = 100
+)
 
-	err = s.Db.MarkFeedFetched(context.Background(), feed_to_fetch.ID)
+// HandlerAgg checks for due feeds once per interval and fetches them on
+// a pool of workers, every feed is due on its own schedule
+//
+//	gator agg [--workers 8] [--per-host 2] [--batch 100] [--timeout 30s] <interval>
+func HandlerAgg(s *State, cmd Command) error {
+	fetchTimeout, err := parseTimeout("fetch_timeout", s.Cfg.FetchTimeout, rssfeed.DefaultTimeout)
 	if err != nil {
 		return err
 	}
 
-	data, err := rssfeed.FetchFeed(context.Background(), feed_to_fetch.Url)
+	flags := flag.NewFlagSet("agg", flag.ContinueOnError)
+	workers := flags.Int("workers", orDefault(s.Cfg.AggWorkers, defaultAggWorkers), "feeds fetched at the same time")
+	perHost := flags.Int("per-host", orDefault(s.Cfg.AggPerHost, defaultAggPerHost), "feeds fetched at the same time from one host, 0 for no limit")
+	batchSize := flags.Int("batch", orDefault(s.Cfg.AggBatchSize, defaultAggBatchSize), "feeds claimed per round")
+	timeout := flags.Duration("timeout", fetchTimeout, "time limit of one feed request")
+
+	err = flags.Parse(cmd.Args)
 	if err != nil {
 		return err
 	}
 
-	for _, item := range data.Channel.Item {
-		time, err := rssfeed.ParseRSSTime(item.PubDate)
-		if err != nil {
-			return fmt.Errorf("failed to parse time: %s, %s", item.PubDate, item.Link)
-		}
+	if flags.NArg() == 0 {
+		fmt.Print("the agg handler expects a single argument, the time between reqs\n")
+		os.Exit(1)
+	}
 
-		_, err = s.Db.CreatePost(context.Background(), database.CreatePostParams{Title: item.Title, Description: sql.NullString{String: item.Description, Valid: item.Description != ""}, PublishedAt: time, Url: item.Link, FeedID: feed_to_fetch.ID})
+	duration, err := time.ParseDuration(flags.Arg(0))
+	if err != nil {
+		fmt.Print("error in duration value\n")
+		os.Exit(1)
+	}
 
-		if err != nil {
-			// Check if it's a PostgreSQL error
-			if pqErr, ok := err.(*pq.Error); ok {
-				// Check for unique violation error code
-				if pqErr.Code == "23505" {
-					fmt.Printf("Post with URL %s already exists, skipping\n", item.Link)
-					continue // Skip this post and continue with the next one
-				}
-			}
-			// For other errors, return the error
-			return fmt.Errorf("failed to create post: %w", err)
-		}
+	if *workers < 1 {
+		return fmt.Errorf("invalid worker count: %d", *workers)
+	}
+	if *perHost < 0 {
+		return fmt.Errorf("invalid per host limit: %d", *perHost)
+	}
+	if *batchSize < 1 {
+		return fmt.Errorf("invalid batch size: %d", *batchSize)
+	}
+	if *timeout <= 0 {
+		return fmt.Errorf("invalid timeout: %s", *timeout)
 	}
 
-	return nil
-}
+	maxFailures := orDefault(s.Cfg.MaxFeedFailures, defaultMaxFeedFailures)
+	if maxFailures < 1 {
+		return fmt.Errorf("invalid max_feed_failures: %d", maxFailures)
+	}
 
-func HandlerAgg(s *State, cmd Command) error {
-	if len(cmd.Args) == 0 {
-		fmt.Print("the agg handler expects a single argument, the time between reqs\n")
-		os.Exit(1)
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "gator"
 	}
 
-	duration, err := time.ParseDuration(cmd.Args[0])
+	minInterval, maxInterval, err := fetchBounds(s.Cfg.MinFetchInterval, s.Cfg.MaxFetchInterval)
 	if err != nil {
-		fmt.Print("error in duration value\n")
-		os.Exit(1)
+		return err
 	}
 
-	fmt.Printf("Collecting feeds every %s\n", duration.String())
+	opts := aggOptions{
+		workers:     *workers,
+		perHost:     *perHost,
+		batchSize:   *batchSize,
+		owner:       fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+		minInterval: minInterval,
+		maxInterval: maxInterval,
+		maxFailures: maxFailures,
+		timeout:     *timeout,
+	}
+
+	fmt.Printf("Collecting feeds every %s with %d workers\n", duration.String(), *workers)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		// a second signal kills the process the default way
+		stop()
+		fmt.Print("Shutting down, waiting for the feeds in flight, interrupt again to force\n")
+	}()
+
+	summary := &aggSummary{}
 
 	ticker := time.NewTicker(duration)
 	defer ticker.Stop()
 
-	for ; ; <-ticker.C {
-		err := scrapeFeeds(s)
-		if err != nil {
-			fmt.Println(err)
+	for {
+		err := scrapeFeeds(ctx, s, opts, summary)
+		if err != nil && ctx.Err() == nil {
+			fmt.Println(describeFetchError(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Printf("Stopped after %s\n", summary)
+			return nil
+		case <-ticker.C:
 		}
 	}
 }
 
+// orDefault returns the configured value, or fallback when the config
+// leaves it unset
+func orDefault(value *int, fallback int) int {
+	if value == nil {
+		return fallback
+	}
+
+	return *value
+}
+
+// parseTimeout reads a duration such as fetch_timeout from the config,
+// fallback when the config leaves it unset
+func parseTimeout(name, value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+
+	return timeout, nil
+}
+
+// HandlerAddFeed adds a feed, a website URL is replaced by the feed it
+// links to, --auto picks the first one instead of asking
+//
+//	gator addfeed [--auto] <name> <url>
 func HandlerAddFeed(s *State, cmd Command, user database.User) error {
-	if len(cmd.Args) < 2 {
+	var args []string
+	auto := false
+	for _, arg := range cmd.Args {
+		if arg == "--auto" {
+			auto = true
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	if len(args) < 2 {
 		fmt.Print("the register handler expects a two arguments, the name and urlf\n")
 		os.Exit(1)
 	}
 
 	fmt.Println(user, s.Cfg.Username)
 
-	feed, err := s.Db.CreateFeed(context.Background(), database.CreateFeedParams{ID: uuid.New(), Name: cmd.Args[0], Url: cmd.Args[1], UserID: user.ID})
+	timeout, err := parseTimeout("fetch_timeout", s.Cfg.FetchTimeout, rssfeed.DefaultTimeout)
+	if err != nil {
+		return err
+	}
+
+	feedURL, err := discoverFeed(args[1], auto, timeout)
+	if err != nil {
+		return err
+	}
+
+	feed, err := s.Db.CreateFeed(context.Background(), database.CreateFeedParams{ID: uuid.New(), Name: args[0], Url: feedURL, UserID: user.ID})
 	if err != nil {
 		fmt.Print("failed to create feed due to next error:\n")
 		fmt.Println(err)
@@ -241,7 +335,14 @@ func HandlerAddFeed(s *State, cmd Command, user database.User) error {
 	return nil
 }
 
+// HandlerGetFeeds lists all feeds, --broken lists the failing and
+// disabled ones with their last error
+//
+//	gator feeds [--broken]
 func HandlerGetFeeds(s *State, cmd Command) error {
+	if len(cmd.Args) > 0 && cmd.Args[0] == "--broken" {
+		return handlerBrokenFeeds(s)
+	}
 
 	feeds, err := s.Db.GetFeeds(context.Background())
 	if err != nil {
@@ -258,12 +359,45 @@ func HandlerGetFeeds(s *State, cmd Command) error {
 		fmt.Printf("Name: %s\n", feed.Name)
 		fmt.Printf("URL: %s\n", feed.Url)
 		fmt.Printf("User: %s\n", user.Name)
+		if feed.DisabledAt.Valid {
+			fmt.Printf("Disabled: %s\n", feed.DisabledAt.Time)
+		}
 
 	}
 
 	return nil
 }
 
+func handlerBrokenFeeds(s *State) error {
+	feeds, err := s.Db.GetBrokenFeeds(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if len(feeds) == 0 {
+		fmt.Print("No broken feeds\n")
+		return nil
+	}
+
+	for _, feed := range feeds {
+		status := "failing"
+		if feed.DisabledAt.Valid {
+			status = fmt.Sprintf("disabled since %s", feed.DisabledAt.Time)
+		}
+
+		lastSuccess := "never"
+		if feed.LastSuccessAt.Valid {
+			lastSuccess = feed.LastSuccessAt.Time.String()
+		}
+
+		fmt.Printf("-----------\n- Name: %s\n -- URL: %s\n -- Status: %s\n -- Failures in a row: %d\n -- Last error: %s\n -- Last success: %s\n", feed.Name, feed.Url, status, feed.ConsecutiveFailures, feed.LastError.String, lastSuccess)
+	}
+
+	fmt.Print("-----------\n")
+
+	return nil
+}
+
 func HandlerFollow(s *State, cmd Command, user database.User) error {
 	if len(cmd.Args) == 0 {
 		fmt.Print("the follow handler expects one arguments, url\n")
@@ -322,10 +456,10 @@ func HandlerBrowse(s *State, cmd Command, user database.User) error {
 	if len(cmd.Args) > 0 {
 		parsedLimit, err := strconv.Atoi(cmd.Args[0])
 		if err != nil {
-			fmt.Errorf("invalid limit value: %s, using default%s", string(cmd.Args[0]), limit)
+			fmt.Printf("invalid limit value: %s, using default %d\n", cmd.Args[0], limit)
+		} else {
+			limit = parsedLimit
 		}
-
-		limit = parsedLimit
 	}
 
 	posts, err := s.Db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{UserID: user.ID, Limit: int32(limit)})
@@ -334,8 +468,103 @@ func HandlerBrowse(s *State, cmd Command, user database.User) error {
 	}
 
 	for _, post := range posts {
-		fmt.Printf("-----------\n- Title: %s\n -- Description: %s\n -- Pub date: %s\n-----------", post.Title, post.Description.String, post.PublishedAt)
+		// plain_text renders the full content, browse only shows the summary
+		fmt.Printf("-----------\n- Title: %s\n -- Feed: %s\n -- Description: %s\n -- Pub date: %s\n -- Read: gator read %s\n", post.Title, post.FeedName, rssfeed.PlainText(post.Description.String), post.PublishedAt, post.ID)
+
+		enclosures, err := s.Db.GetEnclosuresForPost(context.Background(), post.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, enclosure := range enclosures {
+			fmt.Printf(" -- Enclosure: %s\n", formatEnclosure(enclosure))
+		}
+
+		fmt.Print("-----------")
+	}
+
+	return nil
+}
+
+// formatEnclosure renders an enclosure as "url (type, size, duration)"
+func formatEnclosure(enclosure database.Enclosure) string {
+	var details []string
+
+	if enclosure.MimeType.Valid {
+		details = append(details, enclosure.MimeType.String)
+	}
+	if enclosure.Length.Valid {
+		details = append(details, fmt.Sprintf("%.1f MB", float64(enclosure.Length.Int64)/(1<<20)))
+	}
+	if enclosure.DurationSeconds.Valid {
+		details = append(details, (time.Duration(enclosure.DurationSeconds.Int32) * time.Second).String())
+	}
+
+	if len(details) == 0 {
+		return enclosure.Url
+	}
+
+	return fmt.Sprintf("%s (%s)", enclosure.Url, strings.Join(details, ", "))
+}
+
+func HandlerHistory(s *State, cmd Command) error {
+	if len(cmd.Args) == 0 {
+		fmt.Print("the history handler expects one argument, the post id\n")
+		os.Exit(1)
+	}
+
+	postID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id: %s", cmd.Args[0])
+	}
+
+	post, err := s.Db.GetPost(context.Background(), postID)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := s.Db.GetPostRevisions(context.Background(), postID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("-----------\n- Current (updated %s)\n -- Title: %s\n -- Description: %s\n", post.UpdatedAt.Time, post.Title, post.Description.String)
+
+	for _, revision := range revisions {
+		fmt.Printf("-----------\n- Replaced %s\n -- Title: %s\n -- Description: %s\n", revision.CreatedAt, revision.Title, revision.Description.String)
+	}
+
+	fmt.Print("-----------\n")
+
+	return nil
+}
+
+func HandlerRead(s *State, cmd Command) error {
+	if len(cmd.Args) == 0 {
+		fmt.Print("the read handler expects one argument, the post id\n")
+		os.Exit(1)
+	}
+
+	postID, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id: %s", cmd.Args[0])
+	}
+
+	post, err := s.Db.GetPost(context.Background(), postID)
+	if err != nil {
+		return err
 	}
 
+	// posts stored before sanitization have no plain text rendition
+	body := post.PlainText.String
+	if body == "" {
+		body = post.Content.String
+	}
+	if body == "" {
+		body = post.Description.String
+	}
+
+	fmt.Printf("%s\n%s\nPublished: %s\n\n%s\n", post.Title, post.Url, post.PublishedAt, body)
+
 	return nil
 }
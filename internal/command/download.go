@@ -0,0 +1,273 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alexeychuk/Gator/internal/database"
+	"github.com/Alexeychuk/Gator/internal/download"
+
+	"github.com/google/uuid"
+)
+
+const (
+	downloadStatusDownloading = "downloading"
+	downloadStatusComplete    = "complete"
+	downloadStatusFailed      = "failed"
+	downloadStatusDeleted     = "deleted"
+	// skipped episodes were larger than the size limit, they are only
+	// tried again once the limit is raised
+	downloadStatusSkipped = "skipped"
+)
+
+// defaultDownloadTimeout is how long a download may stall when the config sets no limit
+const defaultDownloadTimeout = time.Minute
+
+// HandlerDownload fetches podcast episodes of followed feeds, keeping only
+// the newest episodes of every feed on disk
+//
+//	gator download [--dir path] [--max-size 500MB] [--keep 5] [--timeout 1m]
+func HandlerDownload(s *State, cmd Command, user database.User) error {
+	downloadTimeout, err := parseTimeout("download_timeout", s.Cfg.DownloadTimeout, defaultDownloadTimeout)
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("download", flag.ContinueOnError)
+	dir := flags.String("dir", s.Cfg.DownloadDir, "directory to store episodes in")
+	maxSize := flags.String("max-size", strconv.FormatInt(s.Cfg.MaxDownloadSize, 10), "skip episodes larger than this, e.g. 500MB, 0 for no limit")
+	keep := flags.Int("keep", s.Cfg.KeepEpisodes, "episodes to keep per feed, 0 keeps all")
+	timeout := flags.Duration("timeout", downloadTimeout, "give up on a download that receives nothing for this long, 0 waits forever")
+
+	err = flags.Parse(cmd.Args)
+	if err != nil {
+		return err
+	}
+
+	maxBytes, err := parseSize(*maxSize)
+	if err != nil {
+		return err
+	}
+
+	if *dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		*dir = filepath.Join(home, "gator", "downloads")
+	}
+
+	episodes, err := s.Db.GetEpisodesForUser(context.Background(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	// episodes come newest first per feed, count their posts to apply
+	// retention so an episode with several files counts once
+	perFeed := make(map[uuid.UUID]int)
+	counted := make(map[uuid.UUID]bool)
+
+	for _, episode := range episodes {
+		if !isEpisode(episode) {
+			continue
+		}
+
+		if !counted[episode.PostID] {
+			counted[episode.PostID] = true
+			perFeed[episode.FeedID]++
+		}
+
+		if *keep > 0 && perFeed[episode.FeedID] > *keep {
+			err = removeEpisode(s, episode)
+			if err != nil {
+				fmt.Printf("failed to remove %s: %s\n", episode.PostTitle, err)
+			}
+			continue
+		}
+
+		err = downloadEpisode(s, episode, *dir, maxBytes, *timeout)
+		if err != nil {
+			fmt.Printf("failed to download %s: %s\n", episode.PostTitle, err)
+		}
+	}
+
+	return nil
+}
+
+func downloadEpisode(s *State, episode database.GetEpisodesForUserRow, dir string, maxBytes int64, timeout time.Duration) error {
+	existing, err := s.Db.GetDownloadByEnclosure(context.Background(), episode.ID)
+	if err == nil && existing.Status == downloadStatusComplete {
+		if _, statErr := os.Stat(existing.Path); statErr == nil {
+			return nil
+		}
+	} else if err == nil && existing.Status == downloadStatusSkipped {
+		// bytes holds the limit the episode exceeded
+		if maxBytes > 0 && maxBytes <= existing.Bytes {
+			return nil
+		}
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	filePath := episodePath(dir, episode)
+
+	record, err := s.Db.UpsertDownload(context.Background(), database.UpsertDownloadParams{
+		EnclosureID: episode.ID,
+		FeedID:      episode.FeedID,
+		Path:        filePath,
+		Status:      downloadStatusDownloading,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Downloading %s - %s\n", episode.FeedName, episode.PostTitle)
+
+	size, err := download.File(context.Background(), episode.Url, filePath, maxBytes, timeout)
+	if errors.Is(err, download.ErrTooLarge) {
+		fmt.Printf("Skipped %s - %s: %s\n", episode.FeedName, episode.PostTitle, err)
+		return s.Db.UpdateDownloadStatus(context.Background(), database.UpdateDownloadStatusParams{
+			ID:     record.ID,
+			Status: downloadStatusSkipped,
+			Bytes:  maxBytes,
+			Error:  sql.NullString{String: err.Error(), Valid: true},
+		})
+	}
+	if err != nil {
+		statusErr := s.Db.UpdateDownloadStatus(context.Background(), database.UpdateDownloadStatusParams{
+			ID:     record.ID,
+			Status: downloadStatusFailed,
+			Bytes:  size,
+			Error:  sql.NullString{String: err.Error(), Valid: true},
+		})
+		if statusErr != nil {
+			return statusErr
+		}
+		return err
+	}
+
+	return s.Db.UpdateDownloadStatus(context.Background(), database.UpdateDownloadStatusParams{
+		ID:     record.ID,
+		Status: downloadStatusComplete,
+		Bytes:  size,
+	})
+}
+
+// removeEpisode deletes a downloaded episode that fell out of the retention window
+func removeEpisode(s *State, episode database.GetEpisodesForUserRow) error {
+	existing, err := s.Db.GetDownloadByEnclosure(context.Background(), episode.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if existing.Status == downloadStatusDeleted {
+		return nil
+	}
+
+	err = removeDownloadFiles(existing.Path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed %s - %s\n", episode.FeedName, episode.PostTitle)
+
+	return s.Db.UpdateDownloadStatus(context.Background(), database.UpdateDownloadStatusParams{
+		ID:     existing.ID,
+		Status: downloadStatusDeleted,
+	})
+}
+
+// removeDownloadFiles deletes a download and what is left of an
+// unfinished one, files that are already gone are not an error
+func removeDownloadFiles(path string) error {
+	for _, filePath := range []string{path, path + ".part"} {
+		err := os.Remove(filePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// isEpisode skips images and other enclosures that are not audio or video
+func isEpisode(episode database.GetEpisodesForUserRow) bool {
+	if !episode.MimeType.Valid {
+		return true
+	}
+
+	return strings.HasPrefix(episode.MimeType.String, "audio/") || strings.HasPrefix(episode.MimeType.String, "video/")
+}
+
+// episodePath builds <dir>/<feed>/<date> <title> <id><ext>, the start of
+// the enclosure id keeps episodes with the same date and title apart
+func episodePath(dir string, episode database.GetEpisodesForUserRow) string {
+	ext := path.Ext(strings.SplitN(episode.Url, "?", 2)[0])
+	if len(ext) > 5 {
+		ext = ""
+	}
+
+	name := episode.PublishedAt.Format("2006-01-02") + " " + safeFileName(episode.PostTitle) + " " + episode.ID.String()[:8] + ext
+
+	return filepath.Join(dir, safeFileName(episode.FeedName), name)
+}
+
+// safeFileName makes name usable as one path element, a name made of
+// dots only would point at the directory itself or its parent
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+
+	if strings.Trim(name, ".") == "" {
+		name = strings.Repeat("_", max(len(name), 1))
+	}
+
+	return name
+}
+
+// parseSize accepts plain bytes or a K, M or G suffixed value, e.g. "500MB"
+func parseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	value = strings.TrimSuffix(value, "B")
+
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1 << 30
+	}
+	value = strings.TrimRight(value, "KMG")
+
+	if value == "" {
+		return 0, nil
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+
+	return size * multiplier, nil
+}
@@ -0,0 +1,177 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alexeychuk/Gator/internal/database"
+	rssfeed "github.com/Alexeychuk/Gator/internal/rssFeed"
+)
+
+// HandlerFeed manages the settings of a single feed
+//
+//	gator feed timezone <url> <zone|clear>
+//	gator feed interval <url> <duration|auto>
+//	gator feed enable <url>
+func HandlerFeed(s *State, cmd Command) error {
+	if len(cmd.Args) == 0 {
+		fmt.Print("the feed handler expects a subcommand: timezone, interval, enable\n")
+		os.Exit(1)
+	}
+
+	switch cmd.Args[0] {
+	case "timezone":
+		return handlerFeedTimezone(s, cmd.Args[1:])
+	case "interval":
+		return handlerFeedInterval(s, cmd.Args[1:])
+	case "enable":
+		return handlerFeedEnable(s, cmd.Args[1:])
+	default:
+		return fmt.Errorf("unknown feed subcommand: %s", cmd.Args[0])
+	}
+}
+
+// handlerFeedTimezone sets the zone used for dates the feed publishes
+// without an offset, "clear" goes back to UTC
+func handlerFeedTimezone(s *State, args []string) error {
+	if len(args) < 2 {
+		fmt.Print("the feed timezone handler expects two arguments, the url and a zone like Europe/Moscow or clear\n")
+		os.Exit(1)
+	}
+
+	timezone := sql.NullString{}
+	if args[1] != "clear" {
+		_, err := time.LoadLocation(args[1])
+		if err != nil {
+			return fmt.Errorf("unknown timezone %s: %w", args[1], err)
+		}
+		timezone = sql.NullString{String: args[1], Valid: true}
+	}
+
+	updated, err := s.Db.SetFeedTimezone(context.Background(), database.SetFeedTimezoneParams{Url: args[0], Timezone: timezone})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("feed %s not found", args[0])
+	}
+
+	fmt.Printf("Timezone of %s set to %s\n", args[0], args[1])
+
+	return nil
+}
+
+// handlerFeedInterval pins how often the feed is fetched, "auto" goes back
+// to the adaptive schedule. The feed is fetched on the next round either way.
+func handlerFeedInterval(s *State, args []string) error {
+	if len(args) < 2 {
+		fmt.Print("the feed interval handler expects two arguments, the url and a duration like 30m or auto\n")
+		os.Exit(1)
+	}
+
+	interval := sql.NullInt32{}
+	if args[1] != "auto" {
+		duration, err := time.ParseDuration(args[1])
+		if err != nil || duration < time.Minute {
+			return fmt.Errorf("invalid interval %s, expected a duration of at least 1m", args[1])
+		}
+		interval = sql.NullInt32{Int32: int32(duration.Seconds()), Valid: true}
+	}
+
+	updated, err := s.Db.SetFeedFetchInterval(context.Background(), database.SetFeedFetchIntervalParams{Url: args[0], FetchIntervalSeconds: interval})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("feed %s not found", args[0])
+	}
+
+	fmt.Printf("Fetch interval of %s set to %s\n", args[0], args[1])
+
+	return nil
+}
+
+// handlerFeedEnable puts a disabled feed back in rotation and clears its
+// failure count, it is fetched on the next round
+func handlerFeedEnable(s *State, args []string) error {
+	if len(args) == 0 {
+		fmt.Print("the feed enable handler expects one argument, the url\n")
+		os.Exit(1)
+	}
+
+	updated, err := s.Db.EnableFeed(context.Background(), args[0])
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return fmt.Errorf("feed %s not found", args[0])
+	}
+
+	fmt.Printf("Feed %s enabled\n", args[0])
+
+	return nil
+}
+
+// feedLocation returns the zone naive feed dates are read in
+func feedLocation(feed database.Feed) *time.Location {
+	if !feed.Timezone.Valid {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(feed.Timezone.String)
+	if err != nil {
+		fmt.Printf("invalid timezone %s for feed %s, using UTC\n", feed.Timezone.String, feed.Name)
+		return time.UTC
+	}
+
+	return loc
+}
+
+// discoverFeed turns the URL given to addfeed into a feed URL. When a
+// website links to several feeds the user picks one, unless auto is set
+// or stdin is not a terminal, then the first one is taken.
+func discoverFeed(pageURL string, auto bool, timeout time.Duration) (string, error) {
+	candidates, err := rssfeed.Discover(context.Background(), pageURL, timeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to find a feed at %s: %w", pageURL, err)
+	}
+
+	if len(candidates) == 1 || auto || !isTerminal(os.Stdin) {
+		if candidates[0].URL != pageURL {
+			fmt.Printf("Found feed %s\n", candidates[0].URL)
+		}
+		return candidates[0].URL, nil
+	}
+
+	fmt.Printf("Found %d feeds at %s:\n", len(candidates), pageURL)
+	for i, candidate := range candidates {
+		fmt.Printf("%d) %s - %s (%s, %d items)\n", i+1, candidate.Title, candidate.URL, candidate.Format, candidate.Items)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Choose a feed [1-%d]: ", len(candidates))
+
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+
+		choice, err := strconv.Atoi(strings.TrimSpace(line))
+		if err == nil && choice >= 1 && choice <= len(candidates) {
+			return candidates[choice-1].URL, nil
+		}
+	}
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
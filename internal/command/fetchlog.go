@@ -0,0 +1,89 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Alexeychuk/Gator/internal/database"
+)
+
+// HandlerFetchLog prints the most recent fetches, of every feed or of the
+// one given by url or name
+//
+//	gator fetchlog [--limit 20] [feed]
+func HandlerFetchLog(s *State, cmd Command) error {
+	flags := flag.NewFlagSet("fetchlog", flag.ContinueOnError)
+	limit := flags.Int("limit", 20, "number of fetches to show")
+
+	err := flags.Parse(cmd.Args)
+	if err != nil {
+		return err
+	}
+
+	if *limit < 1 {
+		return fmt.Errorf("invalid limit: %d", *limit)
+	}
+
+	var fetches []database.GetFeedFetchesRow
+
+	if flags.NArg() > 0 {
+		rows, err := s.Db.GetFeedFetchesForFeed(context.Background(), database.GetFeedFetchesForFeedParams{Url: flags.Arg(0), Limit: int32(*limit)})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			fetches = append(fetches, database.GetFeedFetchesRow(row))
+		}
+	} else {
+		fetches, err = s.Db.GetFeedFetches(context.Background(), int32(*limit))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(fetches) == 0 {
+		fmt.Print("No fetches logged\n")
+		return nil
+	}
+
+	for _, fetch := range fetches {
+		fmt.Printf("%s  %s  %s\n", fetch.StartedAt.Format(time.DateTime), fetch.FeedName, describeFeedFetch(fetch))
+		if fetch.Error.Valid {
+			fmt.Printf(" -- error: %s\n", fetch.Error.String)
+		}
+		if fetch.MovedTo.Valid {
+			fmt.Printf(" -- moved to: %s\n", fetch.MovedTo.String)
+		}
+	}
+
+	return nil
+}
+
+// describeFeedFetch renders status, size, duration and item counts
+func describeFeedFetch(fetch database.GetFeedFetchesRow) string {
+	var parts []string
+
+	if fetch.HttpStatus.Valid {
+		parts = append(parts, fmt.Sprintf("HTTP %d", fetch.HttpStatus.Int32))
+	} else {
+		parts = append(parts, "no response")
+	}
+
+	if fetch.Bytes.Valid {
+		parts = append(parts, fmt.Sprintf("%.1f KB", float64(fetch.Bytes.Int64)/(1<<10)))
+	}
+
+	parts = append(parts, (time.Duration(fetch.DurationMs) * time.Millisecond).String())
+
+	switch {
+	case fetch.NotModified:
+		parts = append(parts, "not modified")
+	case !fetch.Error.Valid || fetch.ItemsInserted+fetch.ItemsUpdated+fetch.ItemsDuplicate+fetch.ItemsRejected > 0:
+		parts = append(parts, fmt.Sprintf("%d new, %d updated, %d duplicates, %d rejected", fetch.ItemsInserted, fetch.ItemsUpdated, fetch.ItemsDuplicate, fetch.ItemsRejected))
+	}
+
+	return strings.Join(parts, ", ")
+}
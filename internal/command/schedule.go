@@ -0,0 +1,184 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Alexeychuk/Gator/internal/database"
+	rssfeed "github.com/Alexeychuk/Gator/internal/rssFeed"
+)
+
+const (
+	defaultMinFetchInterval = 10 * time.Minute
+	defaultMaxFetchInterval = 24 * time.Hour
+	// defaultFetchInterval is used until a feed has enough dated posts
+	defaultFetchInterval = time.Hour
+	// postingSample is how many of the newest items the posting frequency is taken from
+	postingSample = 20
+	// defaultMaxFeedFailures consecutive failures disable a feed
+	defaultMaxFeedFailures = 10
+)
+
+// fetchBounds reads min_fetch_interval and max_fetch_interval from the config
+func fetchBounds(minValue, maxValue string) (time.Duration, time.Duration, error) {
+	minInterval, maxInterval := defaultMinFetchInterval, defaultMaxFetchInterval
+
+	if minValue != "" {
+		parsed, err := time.ParseDuration(minValue)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid min_fetch_interval: %w", err)
+		}
+		minInterval = parsed
+	}
+
+	if maxValue != "" {
+		parsed, err := time.ParseDuration(maxValue)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid max_fetch_interval: %w", err)
+		}
+		maxInterval = parsed
+	}
+
+	if minInterval > maxInterval {
+		return 0, 0, fmt.Errorf("min_fetch_interval %s is above max_fetch_interval %s", minInterval, maxInterval)
+	}
+
+	return minInterval, maxInterval, nil
+}
+
+// nextFetchDelay decides when the feed is due again. A failed feed backs
+// off exponentially and a manual interval wins over the rest, otherwise
+// the feed is fetched about twice per typical gap between its posts,
+// never sooner than its ttl, syndication period or max-age allow and
+// within the configured bounds. A Retry-After from the server is always
+// honored and the skip hours and days of the feed are always kept.
+func nextFetchDelay(feed database.Feed, result *rssfeed.FetchResult, fetchErr error, opts aggOptions) time.Duration {
+	now := time.Now()
+
+	var delay time.Duration
+
+	// a 304 or a failure keeps the skip hours of the last full fetch
+	schedule := storedSchedule(feed)
+	if result != nil && result.Feed != nil {
+		schedule = result.Feed.Schedule
+	}
+
+	switch {
+	case fetchErr != nil:
+		// back off exponentially, starting at the minimum interval, the
+		// cap is checked before shifting so a long interval cannot overflow
+		failures := min(feed.ConsecutiveFailures, 16)
+		delay = opts.maxInterval
+		if opts.minInterval <= opts.maxInterval>>failures {
+			delay = opts.minInterval << failures
+		}
+
+	case feed.FetchIntervalSeconds.Valid:
+		delay = time.Duration(feed.FetchIntervalSeconds.Int32) * time.Second
+
+	case result != nil && result.Feed != nil:
+		delay = postingInterval(result.Feed.Items, feedLocation(feed))
+		if delay == 0 {
+			delay = defaultFetchInterval
+		}
+		delay = max(delay, schedule.TTL, schedule.UpdatePeriod, result.MaxAge)
+		delay = min(max(delay, opts.minInterval), opts.maxInterval)
+
+	default:
+		// not modified, keep the interval of the previous fetch
+		delay = previousInterval(feed)
+		if result != nil {
+			delay = max(delay, result.MaxAge)
+		}
+		delay = min(max(delay, opts.minInterval), opts.maxInterval)
+	}
+
+	var statusErr *rssfeed.StatusError
+	if result != nil {
+		delay = max(delay, result.RetryAfter)
+	} else if errors.As(fetchErr, &statusErr) {
+		delay = max(delay, statusErr.RetryAfter)
+	}
+
+	next := now.Add(delay)
+	// step past skipped hours, a week of them at most
+	for i := 0; i < 7*24 && schedule.Skips(next); i++ {
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	return next.Sub(now)
+}
+
+// skipMasks packs the skip hours and days of a schedule into the bit
+// masks stored in the skip_hours and skip_days columns
+func skipMasks(schedule rssfeed.Schedule) (hours int32, days int32) {
+	for _, hour := range schedule.SkipHours {
+		hours |= 1 << hour
+	}
+	for _, day := range schedule.SkipDays {
+		days |= 1 << day
+	}
+
+	return hours, days
+}
+
+// storedSchedule unpacks the skip hours and days saved with the feed
+func storedSchedule(feed database.Feed) rssfeed.Schedule {
+	var schedule rssfeed.Schedule
+
+	for hour := range 24 {
+		if feed.SkipHours&(1<<hour) != 0 {
+			schedule.SkipHours = append(schedule.SkipHours, hour)
+		}
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if feed.SkipDays&(1<<day) != 0 {
+			schedule.SkipDays = append(schedule.SkipDays, day)
+		}
+	}
+
+	return schedule
+}
+
+// postingInterval is half the median gap between the newest dated items,
+// 0 when the feed has fewer than two of them
+func postingInterval(items []rssfeed.Item, loc *time.Location) time.Duration {
+	var dates []time.Time
+
+	for _, item := range items {
+		date, err := rssfeed.ParseTime(item.PubDate, loc)
+		if err == nil {
+			dates = append(dates, date)
+		}
+	}
+
+	if len(dates) < 2 {
+		return 0
+	}
+
+	slices.SortFunc(dates, func(a, b time.Time) int { return b.Compare(a) })
+	if len(dates) > postingSample {
+		dates = dates[:postingSample]
+	}
+
+	gaps := make([]time.Duration, 0, len(dates)-1)
+	for i := 1; i < len(dates); i++ {
+		gaps = append(gaps, dates[i-1].Sub(dates[i]))
+	}
+	slices.Sort(gaps)
+
+	return gaps[len(gaps)/2] / 2
+}
+
+// previousInterval is the delay chosen after the last fetch. After a
+// failure that delay is a backoff, so a feed that recovers starts over
+// from the default interval.
+func previousInterval(feed database.Feed) time.Duration {
+	if !feed.NextFetchAt.Valid || !feed.LastFetchedAt.Valid || feed.ConsecutiveFailures > 0 {
+		return defaultFetchInterval
+	}
+
+	return feed.NextFetchAt.Time.Sub(feed.LastFetchedAt.Time)
+}
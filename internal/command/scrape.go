@@ -0,0 +1,689 @@
+package command
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Alexeychuk/Gator/internal/database"
+	rssfeed "github.com/Alexeychuk/Gator/internal/rssFeed"
+
+	"github.com/lib/pq"
+)
+
+type itemOutcome int
+
+const (
+	itemRejected itemOutcome = iota
+	itemInserted
+	itemUpdated
+	itemDuplicate
+)
+
+// errInvalidItem marks rejections caused by the item itself, they fail
+// the same way on every fetch
+var errInvalidItem = errors.New("invalid item")
+
+type itemRejection struct {
+	Item      string `json:"item"`
+	Reason    string `json:"reason"`
+	Permanent bool   `json:"permanent"`
+}
+
+// fetchReport sums up what happened to the items of one fetch
+type fetchReport struct {
+	Inserted   int
+	Updated    int
+	Duplicates int
+	Rejected   []itemRejection
+}
+
+// retryable reports whether some items were rejected for a reason that
+// may go away, such as a database error
+func (r *fetchReport) retryable() bool {
+	for _, rejection := range r.Rejected {
+		if !rejection.Permanent {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fetchReport) String() string {
+	return fmt.Sprintf("%d new, %d updated, %d duplicates, %d rejected", r.Inserted, r.Updated, r.Duplicates, len(r.Rejected))
+}
+
+// hashMatchMinText is how many characters of text a guid-less item needs
+// before a post with the same content under another link counts as the
+// same post, see storePost
+const hashMatchMinText = 200
+
+// feedLease is how long a claimed feed stays reserved for this process,
+// counted again when a worker starts on it. Another aggregator takes the
+// feed over once the lease runs out.
+const feedLease = 15 * time.Minute
+
+// aggOptions controls one aggregation round, see HandlerAgg
+type aggOptions struct {
+	workers   int
+	perHost   int
+	batchSize int
+	// owner identifies this aggregator in the feed leases
+	owner string
+	// bounds of the adaptive refresh interval, see nextFetchDelay
+	minInterval time.Duration
+	maxInterval time.Duration
+	// maxFailures consecutive failures disable a feed
+	maxFailures int
+	// timeout limits the request of one feed, so a server that never
+	// answers cannot hold a worker and its host slot
+	timeout time.Duration
+}
+
+// aggSummary totals the work of an agg run for the report printed on exit
+type aggSummary struct {
+	mu          sync.Mutex
+	rounds      int
+	fetched     int
+	notModified int
+	failed      int
+	interrupted int
+	inserted    int
+	updated     int
+}
+
+func (a *aggSummary) addRound() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rounds++
+}
+
+func (a *aggSummary) record(result *rssfeed.FetchResult, report *fetchReport, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		a.interrupted++
+	case err != nil:
+		a.failed++
+	case result.NotModified:
+		a.notModified++
+	default:
+		a.fetched++
+	}
+
+	if report != nil {
+		a.inserted += report.Inserted
+		a.updated += report.Updated
+	}
+}
+
+func (a *aggSummary) String() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return fmt.Sprintf("%d rounds, %d feeds fetched, %d not modified, %d failed, %d interrupted, %d new posts, %d updated posts",
+		a.rounds, a.fetched, a.notModified, a.failed, a.interrupted, a.inserted, a.updated)
+}
+
+// scrapeFeeds claims the feeds that are due and fetches them concurrently,
+// at most perHost at a time from one host. Claimed rows are leased, so
+// several aggregators can share one database. Cancelling ctx aborts the
+// fetches in flight and hands the unfinished feeds back right away.
+func scrapeFeeds(ctx context.Context, s *State, opts aggOptions, summary *aggSummary) error {
+	feeds, err := s.Db.ClaimFeedsToFetch(ctx, database.ClaimFeedsToFetchParams{
+		LeaseOwner:   sql.NullString{String: opts.owner, Valid: true},
+		LeaseSeconds: feedLease.Seconds(),
+		BatchSize:    int32(opts.batchSize),
+	})
+	if err != nil {
+		return err
+	}
+
+	summary.addRound()
+
+	jobs := make(chan database.Feed)
+	hosts := newHostLimiter(opts.perHost)
+
+	var wg sync.WaitGroup
+	for range min(opts.workers, len(feeds)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for feed := range jobs {
+				// after a shutdown request the remaining feeds are only handed back
+				if ctx.Err() != nil {
+					returnFeed(s, feed, opts)
+					continue
+				}
+
+				release := hosts.acquire(feed.Url)
+				if !renewFeed(s, feed, opts) {
+					release()
+					continue
+				}
+
+				startedAt := time.Now()
+				result, report, err := scrapeFeed(ctx, s, feed, opts.timeout)
+				release()
+
+				summary.record(result, report, err)
+				saveFeedFetch(s, feed, startedAt, result, report, err)
+
+				if errors.Is(err, context.Canceled) {
+					fmt.Printf("Feed %s interrupted\n", feed.Name)
+					returnFeed(s, feed, opts)
+					continue
+				}
+
+				delay := nextFetchDelay(feed, result, err, opts)
+				switch {
+				case err != nil:
+					fmt.Println(describeFetchError(err))
+					recordFailure(s, feed, err, opts)
+				default:
+					recordErr := s.Db.RecordFeedSuccess(context.Background(), feed.ID)
+					if recordErr != nil {
+						fmt.Printf("failed to record success of feed %s: %s\n", feed.Name, recordErr)
+					}
+					saveSchedule(s, feed, result)
+				}
+
+				// the move happens while the lease is held, so no other
+				// aggregator fetches the feed under its old url meanwhile
+				if err == nil && movedTo(feed, result) != "" {
+					moveErr := moveFeed(s, feed, result.PermanentURL)
+					if moveErr != nil {
+						fmt.Printf("failed to move feed %s to %s: %s\n", feed.Name, result.PermanentURL, moveErr)
+					}
+				}
+
+				// failed feeds are released too and scheduled like the rest,
+				// releasing a feed that was merged away does nothing
+				releaseFeed(s, feed, delay, opts)
+			}
+		}()
+	}
+
+	for _, feed := range feeds {
+		jobs <- feed
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return nil
+}
+
+// recordFailure counts a failed fetch against the feed, which is disabled
+// once it fails opts.maxFailures times in a row
+func recordFailure(s *State, feed database.Feed, fetchErr error, opts aggOptions) {
+	failure, err := s.Db.RecordFeedFailure(context.Background(), database.RecordFeedFailureParams{
+		LastError:   sql.NullString{String: fetchErr.Error(), Valid: true},
+		MaxFailures: int32(opts.maxFailures),
+		ID:          feed.ID,
+	})
+	if err != nil {
+		fmt.Printf("failed to record failure of feed %s: %s\n", feed.Name, err)
+		return
+	}
+
+	if failure.DisabledAt.Valid && !feed.DisabledAt.Valid {
+		fmt.Printf("Feed %s disabled after %d failures in a row, enable it with: gator feed enable %s\n", feed.Name, failure.ConsecutiveFailures, feed.Url)
+	}
+}
+
+// saveSchedule keeps the skip hours and days of a full fetch, so they
+// also apply after a 304 or a failure
+func saveSchedule(s *State, feed database.Feed, result *rssfeed.FetchResult) {
+	if result == nil || result.Feed == nil {
+		return
+	}
+
+	hours, days := skipMasks(result.Feed.Schedule)
+	if hours == feed.SkipHours && days == feed.SkipDays {
+		return
+	}
+
+	err := s.Db.SetFeedSkips(context.Background(), database.SetFeedSkipsParams{ID: feed.ID, SkipHours: hours, SkipDays: days})
+	if err != nil {
+		fmt.Printf("failed to save skip hours of feed %s: %s\n", feed.Name, err)
+	}
+}
+
+// renewFeed restarts the lease of a feed when a worker gets to it, the
+// last feeds of a batch may wait longer than the lease. It reports false
+// when the lease ran out and another aggregator claimed the feed.
+func renewFeed(s *State, feed database.Feed, opts aggOptions) bool {
+	renewed, err := s.Db.RenewFeedLease(context.Background(), database.RenewFeedLeaseParams{
+		LeaseSeconds: feedLease.Seconds(),
+		ID:           feed.ID,
+		LeaseOwner:   sql.NullString{String: opts.owner, Valid: true},
+	})
+	if err != nil {
+		fmt.Printf("failed to renew the lease of feed %s: %s\n", feed.Name, err)
+		return false
+	}
+	if renewed == 0 {
+		fmt.Printf("Feed %s was taken over by another aggregator, skipping it\n", feed.Name)
+		return false
+	}
+
+	return true
+}
+
+// releaseFeed ends the lease on a feed and sets when it is due again. It
+// runs after shutdown was requested, so it does not use the agg context.
+func releaseFeed(s *State, feed database.Feed, delay time.Duration, opts aggOptions) {
+	err := s.Db.ReleaseFeedLease(context.Background(), database.ReleaseFeedLeaseParams{
+		NextFetchSeconds: delay.Seconds(),
+		ID:               feed.ID,
+		LeaseOwner:       sql.NullString{String: opts.owner, Valid: true},
+	})
+	if err != nil {
+		fmt.Printf("failed to release feed %s: %s\n", feed.Name, err)
+	}
+}
+
+// returnFeed ends the lease on a feed that was not fetched to the end.
+// Its schedule is left alone, so it stays due for this or another
+// aggregator and its previous interval is kept.
+func returnFeed(s *State, feed database.Feed, opts aggOptions) {
+	err := s.Db.ReturnFeedLease(context.Background(), database.ReturnFeedLeaseParams{
+		ID:         feed.ID,
+		LeaseOwner: sql.NullString{String: opts.owner, Valid: true},
+	})
+	if err != nil {
+		fmt.Printf("failed to release feed %s: %s\n", feed.Name, err)
+	}
+}
+
+// movedTo returns the url a feed permanently redirected to, or "" when
+// it stayed where it was
+func movedTo(feed database.Feed, result *rssfeed.FetchResult) string {
+	if result == nil || result.PermanentURL == feed.Url {
+		return ""
+	}
+	return result.PermanentURL
+}
+
+// moveFeed points a feed at the url it permanently redirected to. When
+// another feed already has that url the two are merged, the follows,
+// posts, downloads and fetch log of the old feed go to the existing one
+// and the old feed is deleted. Posts the existing feed already had are
+// deleted with their downloads, and the files of those downloads are
+// removed once the merge is committed.
+func moveFeed(s *State, feed database.Feed, newURL string) error {
+	ctx := context.Background()
+
+	target, err := s.Db.GetFeedByUrl(ctx, newURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = s.Db.UpdateFeedUrl(ctx, database.UpdateFeedUrlParams{ID: feed.ID, Url: newURL})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Feed %s moved permanently from %s to %s\n", feed.Name, feed.Url, newURL)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.Db.WithTx(tx)
+
+	err = qtx.MoveFeedFollows(ctx, database.MoveFeedFollowsParams{ToFeedID: target.ID, FromFeedID: feed.ID})
+	if err != nil {
+		return err
+	}
+
+	moved, err := qtx.MovePosts(ctx, database.MovePostsParams{ToFeedID: target.ID, FromFeedID: feed.ID})
+	if err != nil {
+		return err
+	}
+
+	err = qtx.MoveDownloads(ctx, database.MoveDownloadsParams{ToFeedID: target.ID, FromFeedID: feed.ID})
+	if err != nil {
+		return err
+	}
+
+	dropped, err := qtx.GetDownloadsForFeed(ctx, feed.ID)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.MoveFeedFetches(ctx, database.MoveFeedFetchesParams{ToFeedID: target.ID, FromFeedID: feed.ID})
+	if err != nil {
+		return err
+	}
+
+	// posts the target already had are deleted with the old feed
+	err = qtx.DeleteFeed(ctx, feed.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Feed %s moved permanently to %s and merged into feed %s, %d posts moved\n", feed.Name, newURL, target.Name, moved)
+
+	for _, download := range dropped {
+		if download.Status == downloadStatusDeleted {
+			continue
+		}
+
+		err = removeDownloadFiles(download.Path)
+		if err != nil {
+			fmt.Printf("failed to remove %s: %s\n", download.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// scrapeFeed fetches one feed and stores its items, it runs on several
+// goroutines at once so its output is printed in one piece. Database
+// writes are not cancelled with ctx, an interrupted feed stops between
+// items and keeps its old cache headers so the next fetch sees them all.
+func scrapeFeed(ctx context.Context, s *State, feed database.Feed, timeout time.Duration) (*rssfeed.FetchResult, *fetchReport, error) {
+	result, err := rssfeed.FetchFeed(ctx, feed.Url, rssfeed.FetchOptions{ETag: feed.Etag.String, LastModified: feed.LastModified.String, MaxBytes: s.Cfg.MaxFeedSize, Timeout: timeout})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch feed %s: %w", feed.Name, err)
+	}
+
+	if result.NotModified {
+		fmt.Printf("Feed %s not modified\n", feed.Name)
+		return result, nil, nil
+	}
+
+	report := storeItems(ctx, s, feed, result.Feed.Items)
+	if ctx.Err() != nil {
+		return result, report, fmt.Errorf("feed %s stopped after %s: %w", feed.Name, report, ctx.Err())
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Feed %s: %s\n", feed.Name, report)
+	for _, rejection := range report.Rejected {
+		fmt.Fprintf(&out, " -- rejected %s: %s\n", rejection.Item, rejection.Reason)
+	}
+	fmt.Print(out.String())
+
+	// store the validators only once every item is saved, otherwise a
+	// failed run would turn into 304s and lose the remaining items
+	if report.retryable() {
+		return result, report, nil
+	}
+
+	return result, report, s.Db.UpdateFeedCacheHeaders(context.Background(), database.UpdateFeedCacheHeadersParams{
+		ID:           feed.ID,
+		Etag:         sql.NullString{String: result.ETag, Valid: result.ETag != ""},
+		LastModified: sql.NullString{String: result.LastModified, Valid: result.LastModified != ""},
+	})
+}
+
+// hostLimiter caps concurrent requests to a single host
+type hostLimiter struct {
+	mu      sync.Mutex
+	perHost int
+	slots   map[string]chan struct{}
+}
+
+func newHostLimiter(perHost int) *hostLimiter {
+	return &hostLimiter{perHost: perHost, slots: make(map[string]chan struct{})}
+}
+
+// acquire blocks until the host of feedURL has a free slot and returns
+// the function that frees it, a perHost of 0 means no limit
+func (h *hostLimiter) acquire(feedURL string) func() {
+	if h.perHost <= 0 {
+		return func() {}
+	}
+
+	host := feedURL
+	if parsed, err := url.Parse(feedURL); err == nil {
+		host = strings.ToLower(parsed.Hostname())
+	}
+
+	h.mu.Lock()
+	slot, ok := h.slots[host]
+	if !ok {
+		slot = make(chan struct{}, h.perHost)
+		h.slots[host] = slot
+	}
+	h.mu.Unlock()
+
+	slot <- struct{}{}
+
+	return func() { <-slot }
+}
+
+// storeItems saves every item on its own, a failing item is recorded in
+// the report and never stops the rest of the feed. It stops early when
+// ctx is cancelled.
+func storeItems(ctx context.Context, s *State, feed database.Feed, items []rssfeed.Item) *fetchReport {
+	report := &fetchReport{}
+	loc := feedLocation(feed)
+	fetchedAt := time.Now()
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+
+		outcome, err := storeItem(s, feed, item, loc, fetchedAt)
+
+		switch outcome {
+		case itemInserted:
+			report.Inserted++
+		case itemUpdated:
+			report.Updated++
+		case itemDuplicate:
+			report.Duplicates++
+		}
+
+		if err != nil {
+			name := item.Link
+			if name == "" {
+				name = item.DedupKey()
+			}
+			report.Rejected = append(report.Rejected, itemRejection{Item: name, Reason: err.Error(), Permanent: permanentRejection(err)})
+		}
+	}
+
+	return report
+}
+
+// permanentRejection reports whether storing the item will fail again on
+// the next fetch. Postgres data exceptions, class 22, such as a title or
+// url too long for its column, depend on the item only.
+func permanentRejection(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Class() == "22" {
+		return true
+	}
+
+	return errors.Is(err, errInvalidItem)
+}
+
+// storeItem upserts one item. The post and its enclosures are saved in
+// one transaction, so an item is either stored or rejected as a whole.
+func storeItem(s *State, feed database.Feed, item rssfeed.Item, loc *time.Location, fetchedAt time.Time) (itemOutcome, error) {
+	publishedAt, err := rssfeed.ParseTime(item.PubDate, loc)
+	if errors.Is(err, rssfeed.ErrNoDate) {
+		// undated items are stamped with the time we first saw them
+		publishedAt = fetchedAt
+	} else if err != nil {
+		return itemRejected, fmt.Errorf("%w: %w", errInvalidItem, err)
+	}
+
+	tx, err := s.Conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		return itemRejected, fmt.Errorf("failed to store post: %w", err)
+	}
+	defer tx.Rollback()
+
+	outcome, err := storePost(s.Db.WithTx(tx), feed, item, publishedAt)
+	if err != nil {
+		return itemRejected, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return itemRejected, fmt.Errorf("failed to store post: %w", err)
+	}
+
+	return outcome, nil
+}
+
+// storePost matches the item against the stored posts of the feed and
+// inserts or updates it together with its enclosures
+func storePost(q *database.Queries, feed database.Feed, item rssfeed.Item, publishedAt time.Time) (itemOutcome, error) {
+	guid := sql.NullString{String: item.DedupKey(), Valid: true}
+	contentHash := sql.NullString{String: item.ContentHash(), Valid: true}
+
+	// posts stored before guid tracking are matched by their raw link once
+	claimed, err := q.ClaimLegacyPost(context.Background(), database.ClaimLegacyPostParams{FeedID: feed.ID, Url: item.RawLink, Guid: guid, ContentHash: contentHash})
+	if err != nil {
+		return itemRejected, fmt.Errorf("failed to match existing post: %w", err)
+	}
+	if claimed > 0 {
+		return itemDuplicate, nil
+	}
+
+	// without a guid the link is the key, a post with the same content
+	// under a link that changed since is the same post. Short bodies repeat
+	// between posts, such as a weekly "Links" post, so only long ones count.
+	if strings.TrimSpace(item.ID) == "" && item.Link != "" && utf8.RuneCountInString(strings.TrimSpace(item.Text)) >= hashMatchMinText {
+		exists, err := q.PostWithContentHashExists(context.Background(), database.PostWithContentHashExistsParams{FeedID: feed.ID, ContentHash: contentHash})
+		if err != nil {
+			return itemRejected, fmt.Errorf("failed to match existing post: %w", err)
+		}
+		if exists {
+			return itemDuplicate, nil
+		}
+	}
+
+	post, err := q.UpsertPost(context.Background(), database.UpsertPostParams{Title: item.Title, Description: sql.NullString{String: item.Description, Valid: item.Description != ""}, Content: sql.NullString{String: item.Content, Valid: item.Content != ""}, PublishedAt: publishedAt, Url: item.Link, FeedID: feed.ID, Guid: guid, ContentHash: contentHash, PlainText: sql.NullString{String: item.Text, Valid: item.Text != ""}})
+	if errors.Is(err, sql.ErrNoRows) {
+		// the post exists and its content hash is unchanged
+		return itemDuplicate, nil
+	}
+	if err != nil {
+		return itemRejected, fmt.Errorf("failed to store post: %w", err)
+	}
+
+	outcome := itemUpdated
+	if post.Inserted {
+		outcome = itemInserted
+	}
+
+	// an edited post may have gained enclosures, existing ones are updated
+	for _, enclosure := range item.Enclosures {
+		_, err = q.UpsertEnclosure(context.Background(), database.UpsertEnclosureParams{
+			PostID:          post.ID,
+			Url:             enclosure.URL,
+			MimeType:        sql.NullString{String: enclosure.Type, Valid: enclosure.Type != ""},
+			Length:          sql.NullInt64{Int64: enclosure.Length, Valid: enclosure.Length > 0},
+			DurationSeconds: sql.NullInt32{Int32: int32(enclosure.Duration), Valid: enclosure.Duration > 0},
+			ImageUrl:        sql.NullString{String: enclosure.Image, Valid: enclosure.Image != ""},
+		})
+		if err != nil {
+			return itemRejected, fmt.Errorf("failed to store enclosure %s: %w", enclosure.URL, err)
+		}
+	}
+
+	return outcome, nil
+}
+
+// saveFeedFetch adds the outcome of one fetch to the feed_fetches log
+func saveFeedFetch(s *State, feed database.Feed, startedAt time.Time, result *rssfeed.FetchResult, report *fetchReport, fetchErr error) {
+	finishedAt := time.Now()
+
+	params := database.CreateFeedFetchParams{
+		FeedID:     feed.ID,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		DurationMs: int32(finishedAt.Sub(startedAt).Milliseconds()),
+		Rejections: json.RawMessage("[]"),
+	}
+
+	var statusErr *rssfeed.StatusError
+	var bodyErr *rssfeed.BodyError
+	if result != nil {
+		params.HttpStatus = sql.NullInt32{Int32: int32(result.StatusCode), Valid: true}
+		params.Bytes = sql.NullInt64{Int64: result.Bytes, Valid: !result.NotModified}
+		params.NotModified = result.NotModified
+	} else if errors.As(fetchErr, &statusErr) {
+		params.HttpStatus = sql.NullInt32{Int32: int32(statusErr.StatusCode), Valid: true}
+	} else if errors.As(fetchErr, &bodyErr) {
+		params.HttpStatus = sql.NullInt32{Int32: int32(bodyErr.StatusCode), Valid: true}
+		params.Bytes = sql.NullInt64{Int64: bodyErr.Bytes, Valid: true}
+	}
+
+	if fetchErr != nil {
+		params.Error = sql.NullString{String: fetchErr.Error(), Valid: true}
+	} else if newURL := movedTo(feed, result); newURL != "" {
+		params.MovedTo = sql.NullString{String: newURL, Valid: true}
+	}
+
+	if report != nil {
+		params.ItemsInserted = int32(report.Inserted)
+		params.ItemsUpdated = int32(report.Updated)
+		params.ItemsDuplicate = int32(report.Duplicates)
+		params.ItemsRejected = int32(len(report.Rejected))
+
+		if len(report.Rejected) > 0 {
+			rejections, err := json.Marshal(report.Rejected)
+			if err != nil {
+				fmt.Printf("failed to encode rejections of feed %s: %s\n", feed.Name, err)
+			} else {
+				params.Rejections = rejections
+			}
+		}
+	}
+
+	_, err := s.Db.CreateFeedFetch(context.Background(), params)
+	if err != nil {
+		fmt.Printf("failed to log fetch of feed %s: %s\n", feed.Name, err)
+	}
+}
+
+// describeFetchError adds a hint for the user to the rssfeed error kinds
+func describeFetchError(err error) string {
+	var statusErr *rssfeed.StatusError
+
+	switch {
+	case errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone):
+		return fmt.Sprintf("%s, check that the feed url still exists", err)
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("%s, will retry on the next round", err)
+	case errors.Is(err, rssfeed.ErrTooLarge):
+		return fmt.Sprintf("%s, raise max_feed_size in the config to allow it", err)
+	case errors.Is(err, rssfeed.ErrNotAFeed):
+		return fmt.Sprintf("%s, the url may point to a web page instead of its feed", err)
+	case errors.Is(err, rssfeed.ErrMalformed):
+		return fmt.Sprintf("%s, the publisher serves invalid markup", err)
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,76 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	rssfeed "github.com/Alexeychuk/Gator/internal/rssFeed"
+)
+
+// HandlerValidate runs a feed through the parser and reports what gator
+// would make of it, without touching the database
+//
+//	gator validate <url|file>
+func HandlerValidate(s *State, cmd Command) error {
+	if len(cmd.Args) == 0 {
+		fmt.Print("the validate handler expects one argument, a feed url or file\n")
+		os.Exit(1)
+	}
+
+	source := cmd.Args[0]
+
+	feed, err := loadFeed(s, source)
+	if err != nil {
+		return errors.New(describeFetchError(err))
+	}
+
+	fmt.Printf("Source: %s\n", source)
+	fmt.Printf("Format: %s\n", feed.Format)
+	if feed.Encoding == "utf-8" {
+		fmt.Printf("Encoding: %s\n", feed.Encoding)
+	} else {
+		fmt.Printf("Encoding: %s (transcoded to utf-8)\n", feed.Encoding)
+	}
+	fmt.Printf("Title: %s\n", feed.Title)
+	fmt.Printf("Link: %s\n", feed.Link)
+	fmt.Printf("Items: %d\n", len(feed.Items))
+
+	problems := rssfeed.Validate(feed)
+	if len(problems) == 0 {
+		fmt.Print("No problems found\n")
+		return nil
+	}
+
+	fmt.Printf("Problems (%d):\n", len(problems))
+	for _, problem := range problems {
+		fmt.Printf(" - %s\n", problem)
+	}
+
+	return nil
+}
+
+// loadFeed parses a local file when source names one, else fetches it
+func loadFeed(s *State, source string) (*rssfeed.Feed, error) {
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		body, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+
+		return rssfeed.Parse("", "", body)
+	}
+
+	timeout, err := parseTimeout("fetch_timeout", s.Cfg.FetchTimeout, rssfeed.DefaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := rssfeed.FetchFeed(context.Background(), source, rssfeed.FetchOptions{MaxBytes: s.Cfg.MaxFeedSize, Timeout: timeout})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Feed, nil
+}
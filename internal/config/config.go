@@ -10,6 +10,32 @@ import (
 type Config struct {
 	DBUrl    string `json:"db_url"`
 	Username string `json:"current_user_name"`
+
+	// MaxFeedSize limits fetched feed documents in bytes, 0 uses the rssfeed default
+	MaxFeedSize int64 `json:"max_feed_size,omitempty"`
+	// FetchTimeout limits one request for a feed or web page as a Go
+	// duration, e.g. "30s", the rssfeed default when unset
+	FetchTimeout string `json:"fetch_timeout,omitempty"`
+
+	// concurrent fetching, see gator agg, nil when unset so that 0 can
+	// be configured where it means something
+	AggWorkers   *int `json:"agg_workers,omitempty"`
+	AggPerHost   *int `json:"agg_per_host,omitempty"`
+	AggBatchSize *int `json:"agg_batch_size,omitempty"`
+
+	// bounds of the per feed refresh interval as Go durations, e.g. "10m"
+	MinFetchInterval string `json:"min_fetch_interval,omitempty"`
+	MaxFetchInterval string `json:"max_fetch_interval,omitempty"`
+	// MaxFeedFailures consecutive failures disable a feed, nil uses the default
+	MaxFeedFailures *int `json:"max_feed_failures,omitempty"`
+
+	// podcast downloads, see gator download
+	DownloadDir     string `json:"download_dir,omitempty"`
+	MaxDownloadSize int64  `json:"max_download_size,omitempty"`
+	KeepEpisodes    int    `json:"keep_episodes,omitempty"`
+	// DownloadTimeout is how long a download may go without receiving
+	// data, a Go duration like FetchTimeout
+	DownloadTimeout string `json:"download_timeout,omitempty"`
 }
 
 func (c *Config) SetUser(user string) error {
@@ -169,3 +169,20 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const moveFeedFollows = `-- name: MoveFeedFollows :exec
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+SELECT gen_random_uuid(), ff.created_at, NOW(), ff.user_id, $1 FROM feed_follows ff
+WHERE ff.feed_id = $2
+ON CONFLICT (user_id, feed_id) DO NOTHING
+`
+
+type MoveFeedFollowsParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+func (q *Queries) MoveFeedFollows(ctx context.Context, arg MoveFeedFollowsParams) error {
+	_, err := q.db.ExecContext(ctx, moveFeedFollows, arg.ToFeedID, arg.FromFeedID)
+	return err
+}
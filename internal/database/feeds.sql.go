@@ -7,11 +7,77 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const claimFeedsToFetch = `-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET lease_owner = $1, lease_expires_at = NOW() + make_interval(secs => $2::float8)
+WHERE feeds.id IN (
+    SELECT id FROM feeds
+    WHERE (feeds.next_fetch_at IS NULL OR feeds.next_fetch_at <= NOW())
+      AND (feeds.lease_expires_at IS NULL OR feeds.lease_expires_at < NOW())
+      AND feeds.disabled_at IS NULL
+    ORDER BY next_fetch_at NULLS FIRST
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, timezone, lease_owner, lease_expires_at, next_fetch_at, fetch_interval_seconds, consecutive_failures, last_error, last_success_at, disabled_at, skip_hours, skip_days
+`
+
+type ClaimFeedsToFetchParams struct {
+	LeaseOwner   sql.NullString
+	LeaseSeconds float64
+	BatchSize    int32
+}
+
+func (q *Queries) ClaimFeedsToFetch(ctx context.Context, arg ClaimFeedsToFetchParams) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, claimFeedsToFetch, arg.LeaseOwner, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.Timezone,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+			&i.NextFetchAt,
+			&i.FetchIntervalSeconds,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+			&i.SkipHours,
+			&i.SkipDays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -22,7 +88,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, timezone, lease_owner, lease_expires_at, next_fetch_at, fetch_interval_seconds, consecutive_failures, last_error, last_success_at, disabled_at, skip_hours, skip_days
 `
 
 type CreateFeedParams struct {
@@ -52,12 +118,99 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.Timezone,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FetchIntervalSeconds,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.SkipHours,
+		&i.SkipDays,
 	)
 	return i, err
 }
 
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE feeds.id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const enableFeed = `-- name: EnableFeed :execrows
+UPDATE feeds
+SET disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL, updated_at = NOW()
+WHERE feeds.url = $1
+`
+
+func (q *Queries) EnableFeed(ctx context.Context, url string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, enableFeed, url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getBrokenFeeds = `-- name: GetBrokenFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, timezone, lease_owner, lease_expires_at, next_fetch_at, fetch_interval_seconds, consecutive_failures, last_error, last_success_at, disabled_at, skip_hours, skip_days FROM feeds
+WHERE feeds.consecutive_failures > 0 OR feeds.disabled_at IS NOT NULL
+ORDER BY disabled_at NULLS LAST, consecutive_failures DESC
+`
+
+func (q *Queries) GetBrokenFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getBrokenFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.Timezone,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+			&i.NextFetchAt,
+			&i.FetchIntervalSeconds,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+			&i.SkipHours,
+			&i.SkipDays,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeedByUrl = `-- name: GetFeedByUrl :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds 
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, timezone, lease_owner, lease_expires_at, next_fetch_at, fetch_interval_seconds, consecutive_failures, last_error, last_success_at, disabled_at, skip_hours, skip_days FROM feeds 
 WHERE feeds.url = $1
 `
 
@@ -72,12 +225,25 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.Timezone,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.NextFetchAt,
+		&i.FetchIntervalSeconds,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastSuccessAt,
+		&i.DisabledAt,
+		&i.SkipHours,
+		&i.SkipDays,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, timezone, lease_owner, lease_expires_at, next_fetch_at, fetch_interval_seconds, consecutive_failures, last_error, last_success_at, disabled_at, skip_hours, skip_days FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -97,6 +263,19 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.Timezone,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+			&i.NextFetchAt,
+			&i.FetchIntervalSeconds,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastSuccessAt,
+			&i.DisabledAt,
+			&i.SkipHours,
+			&i.SkipDays,
 		); err != nil {
 			return nil, err
 		}
@@ -111,34 +290,193 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
-LIMIT 1
+const markFeedFetched = `-- name: MarkFeedFetched :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), updated_at = NOW()
+WHERE feeds.id = $1
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
-	var i Feed
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Url,
-		&i.UserID,
-		&i.LastFetchedAt,
-	)
+func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
+	return err
+}
+
+const recordFeedFailure = `-- name: RecordFeedFailure :one
+UPDATE feeds
+SET consecutive_failures = consecutive_failures + 1,
+    last_error = $1,
+    disabled_at = CASE WHEN consecutive_failures + 1 >= $2::integer THEN NOW() ELSE disabled_at END
+WHERE feeds.id = $3
+RETURNING consecutive_failures, disabled_at
+`
+
+type RecordFeedFailureParams struct {
+	LastError   sql.NullString
+	MaxFailures int32
+	ID          uuid.UUID
+}
+
+type RecordFeedFailureRow struct {
+	ConsecutiveFailures int32
+	DisabledAt          sql.NullTime
+}
+
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) (RecordFeedFailureRow, error) {
+	row := q.db.QueryRowContext(ctx, recordFeedFailure, arg.LastError, arg.MaxFailures, arg.ID)
+	var i RecordFeedFailureRow
+	err := row.Scan(&i.ConsecutiveFailures, &i.DisabledAt)
 	return i, err
 }
 
-const markFeedFetched = `-- name: MarkFeedFetched :exec
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
 UPDATE feeds
-SET last_fetched_at = NOW(), updated_at = NOW()
+SET consecutive_failures = 0, last_error = NULL, last_success_at = NOW()
 WHERE feeds.id = $1
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
+func (q *Queries) RecordFeedSuccess(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess, id)
+	return err
+}
+
+const releaseFeedLease = `-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), updated_at = NOW(), lease_owner = NULL, lease_expires_at = NULL,
+    next_fetch_at = NOW() + make_interval(secs => $1::float8)
+WHERE feeds.id = $2 AND feeds.lease_owner = $3
+`
+
+type ReleaseFeedLeaseParams struct {
+	NextFetchSeconds float64
+	ID               uuid.UUID
+	LeaseOwner       sql.NullString
+}
+
+func (q *Queries) ReleaseFeedLease(ctx context.Context, arg ReleaseFeedLeaseParams) error {
+	_, err := q.db.ExecContext(ctx, releaseFeedLease, arg.NextFetchSeconds, arg.ID, arg.LeaseOwner)
+	return err
+}
+
+const renewFeedLease = `-- name: RenewFeedLease :execrows
+UPDATE feeds
+SET lease_expires_at = NOW() + make_interval(secs => $1::float8)
+WHERE feeds.id = $2 AND feeds.lease_owner = $3
+`
+
+type RenewFeedLeaseParams struct {
+	LeaseSeconds float64
+	ID           uuid.UUID
+	LeaseOwner   sql.NullString
+}
+
+func (q *Queries) RenewFeedLease(ctx context.Context, arg RenewFeedLeaseParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, renewFeedLease, arg.LeaseSeconds, arg.ID, arg.LeaseOwner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const returnFeedLease = `-- name: ReturnFeedLease :exec
+UPDATE feeds
+SET lease_owner = NULL, lease_expires_at = NULL
+WHERE feeds.id = $1 AND feeds.lease_owner = $2
+`
+
+type ReturnFeedLeaseParams struct {
+	ID         uuid.UUID
+	LeaseOwner sql.NullString
+}
+
+func (q *Queries) ReturnFeedLease(ctx context.Context, arg ReturnFeedLeaseParams) error {
+	_, err := q.db.ExecContext(ctx, returnFeedLease, arg.ID, arg.LeaseOwner)
+	return err
+}
+
+const setFeedFetchInterval = `-- name: SetFeedFetchInterval :execrows
+UPDATE feeds
+SET fetch_interval_seconds = $2, next_fetch_at = NULL, updated_at = NOW()
+WHERE feeds.url = $1
+`
+
+type SetFeedFetchIntervalParams struct {
+	Url                  string
+	FetchIntervalSeconds sql.NullInt32
+}
+
+func (q *Queries) SetFeedFetchInterval(ctx context.Context, arg SetFeedFetchIntervalParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedFetchInterval, arg.Url, arg.FetchIntervalSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setFeedSkips = `-- name: SetFeedSkips :exec
+UPDATE feeds
+SET skip_hours = $2, skip_days = $3
+WHERE feeds.id = $1
+`
+
+type SetFeedSkipsParams struct {
+	ID        uuid.UUID
+	SkipHours int32
+	SkipDays  int32
+}
+
+func (q *Queries) SetFeedSkips(ctx context.Context, arg SetFeedSkipsParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedSkips, arg.ID, arg.SkipHours, arg.SkipDays)
+	return err
+}
+
+const setFeedTimezone = `-- name: SetFeedTimezone :execrows
+UPDATE feeds
+SET timezone = $2, updated_at = NOW()
+WHERE feeds.url = $1
+`
+
+type SetFeedTimezoneParams struct {
+	Url      string
+	Timezone sql.NullString
+}
+
+func (q *Queries) SetFeedTimezone(ctx context.Context, arg SetFeedTimezoneParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setFeedTimezone, arg.Url, arg.Timezone)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateFeedCacheHeaders = `-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds
+SET etag = $2, last_modified = $3
+WHERE feeds.id = $1
+`
+
+type UpdateFeedCacheHeadersParams struct {
+	ID           uuid.UUID
+	Etag         sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) UpdateFeedCacheHeaders(ctx context.Context, arg UpdateFeedCacheHeadersParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedCacheHeaders, arg.ID, arg.Etag, arg.LastModified)
+	return err
+}
+
+const updateFeedUrl = `-- name: UpdateFeedUrl :exec
+UPDATE feeds
+SET url = $2, updated_at = NOW()
+WHERE feeds.id = $1
+`
+
+type UpdateFeedUrlParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateFeedUrl(ctx context.Context, arg UpdateFeedUrlParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedUrl, arg.ID, arg.Url)
 	return err
 }
@@ -6,19 +6,75 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Download struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	EnclosureID uuid.UUID
+	FeedID      uuid.UUID
+	Path        string
+	Status      string
+	Bytes       int64
+	Error       sql.NullString
+}
+
+type Enclosure struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	PostID          uuid.UUID
+	Url             string
+	MimeType        sql.NullString
+	Length          sql.NullInt64
+	DurationSeconds sql.NullInt32
+	ImageUrl        sql.NullString
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                   uuid.UUID
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	Name                 string
+	Url                  string
+	UserID               uuid.UUID
+	LastFetchedAt        sql.NullTime
+	Etag                 sql.NullString
+	LastModified         sql.NullString
+	Timezone             sql.NullString
+	LeaseOwner           sql.NullString
+	LeaseExpiresAt       sql.NullTime
+	NextFetchAt          sql.NullTime
+	FetchIntervalSeconds sql.NullInt32
+	ConsecutiveFailures  int32
+	LastError            sql.NullString
+	LastSuccessAt        sql.NullTime
+	DisabledAt           sql.NullTime
+	SkipHours            int32
+	SkipDays             int32
+}
+
+type FeedFetch struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	FeedID         uuid.UUID
+	ItemsInserted  int32
+	ItemsUpdated   int32
+	ItemsDuplicate int32
+	ItemsRejected  int32
+	Rejections     json.RawMessage
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	DurationMs     int32
+	HttpStatus     sql.NullInt32
+	Bytes          sql.NullInt64
+	NotModified    bool
+	Error          sql.NullString
+	MovedTo        sql.NullString
 }
 
 type FeedFollow struct {
@@ -38,6 +94,20 @@ type Post struct {
 	Description sql.NullString
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Content     sql.NullString
+	Guid        sql.NullString
+	ContentHash sql.NullString
+	PlainText   sql.NullString
+}
+
+type PostRevision struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	PostID      uuid.UUID
+	Title       string
+	Description sql.NullString
+	Content     sql.NullString
+	ContentHash sql.NullString
 }
 
 type User struct {
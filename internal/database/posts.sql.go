@@ -13,33 +13,39 @@ import (
 	"github.com/google/uuid"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts ( title, url, description, published_at, feed_id) 
-VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5
-) RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+const claimLegacyPost = `-- name: ClaimLegacyPost :execrows
+UPDATE posts
+SET guid = $3, content_hash = $4, updated_at = NOW()
+WHERE posts.feed_id = $1 AND posts.url = $2 AND posts.guid IS NULL
 `
 
-type CreatePostParams struct {
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt time.Time
+type ClaimLegacyPostParams struct {
 	FeedID      uuid.UUID
+	Url         string
+	Guid        sql.NullString
+	ContentHash sql.NullString
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
+func (q *Queries) ClaimLegacyPost(ctx context.Context, arg ClaimLegacyPostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimLegacyPost,
 		arg.FeedID,
+		arg.Url,
+		arg.Guid,
+		arg.ContentHash,
 	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPost = `-- name: GetPost :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid, content_hash, plain_text FROM posts
+WHERE posts.id = $1
+`
+
+func (q *Queries) GetPost(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPost, id)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -50,14 +56,19 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Content,
+		&i.Guid,
+		&i.ContentHash,
+		&i.PlainText,
 	)
 	return i, err
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT p.id, p.created_at, p.updated_at, title, p.url, description, published_at, feed_id, f.id, f.created_at, f.updated_at, name, f.url, user_id, last_fetched_at FROM posts p
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.content, p.guid, p.content_hash, p.plain_text, f.name AS feed_name FROM posts p
 JOIN feeds f ON p.feed_id = f.id
-WHERE f.user_id = $1
+JOIN feed_follows ff ON ff.feed_id = f.id
+WHERE ff.user_id = $1
 ORDER BY p.published_at DESC
 LIMIT $2
 `
@@ -68,21 +79,19 @@ type GetPostsForUserParams struct {
 }
 
 type GetPostsForUserRow struct {
-	ID            uuid.UUID
-	CreatedAt     sql.NullTime
-	UpdatedAt     sql.NullTime
-	Title         string
-	Url           string
-	Description   sql.NullString
-	PublishedAt   time.Time
-	FeedID        uuid.UUID
-	ID_2          uuid.UUID
-	CreatedAt_2   time.Time
-	UpdatedAt_2   time.Time
-	Name          string
-	Url_2         string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID          uuid.UUID
+	CreatedAt   sql.NullTime
+	UpdatedAt   sql.NullTime
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	Content     sql.NullString
+	Guid        sql.NullString
+	ContentHash sql.NullString
+	PlainText   sql.NullString
+	FeedName    string
 }
 
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
@@ -103,13 +112,11 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
-			&i.ID_2,
-			&i.CreatedAt_2,
-			&i.UpdatedAt_2,
-			&i.Name,
-			&i.Url_2,
-			&i.UserID,
-			&i.LastFetchedAt,
+			&i.Content,
+			&i.Guid,
+			&i.ContentHash,
+			&i.PlainText,
+			&i.FeedName,
 		); err != nil {
 			return nil, err
 		}
@@ -123,3 +130,137 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const movePosts = `-- name: MovePosts :execrows
+UPDATE posts
+SET feed_id = $1, updated_at = NOW()
+WHERE posts.feed_id = $2
+AND NOT EXISTS (
+    SELECT 1 FROM posts existing
+    WHERE existing.feed_id = $1 AND existing.guid = posts.guid
+)
+`
+
+type MovePostsParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+func (q *Queries) MovePosts(ctx context.Context, arg MovePostsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, movePosts, arg.ToFeedID, arg.FromFeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const postWithContentHashExists = `-- name: PostWithContentHashExists :one
+SELECT EXISTS (
+    SELECT 1 FROM posts
+    WHERE posts.feed_id = $1 AND posts.content_hash = $2
+)
+`
+
+type PostWithContentHashExistsParams struct {
+	FeedID      uuid.UUID
+	ContentHash sql.NullString
+}
+
+func (q *Queries) PostWithContentHashExists(ctx context.Context, arg PostWithContentHashExistsParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, postWithContentHashExists, arg.FeedID, arg.ContentHash)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const upsertPost = `-- name: UpsertPost :one
+WITH previous AS (
+    SELECT id, title, description, content, content_hash FROM posts
+    WHERE posts.feed_id = $6 AND posts.guid = $7
+), revision AS (
+    INSERT INTO post_revisions (post_id, title, description, content, content_hash)
+    SELECT id, title, description, content, content_hash FROM previous
+    WHERE previous.content_hash IS DISTINCT FROM $8
+)
+INSERT INTO posts ( title, url, description, content, published_at, feed_id, guid, content_hash, plain_text) 
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    content = EXCLUDED.content,
+    plain_text = EXCLUDED.plain_text,
+    content_hash = EXCLUDED.content_hash,
+    updated_at = NOW()
+WHERE posts.content_hash IS DISTINCT FROM EXCLUDED.content_hash
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, content, guid, content_hash, plain_text, (xmax = 0)::boolean AS inserted
+`
+
+type UpsertPostParams struct {
+	Title       string
+	Url         string
+	Description sql.NullString
+	Content     sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	Guid        sql.NullString
+	ContentHash sql.NullString
+	PlainText   sql.NullString
+}
+
+type UpsertPostRow struct {
+	ID          uuid.UUID
+	CreatedAt   sql.NullTime
+	UpdatedAt   sql.NullTime
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt time.Time
+	FeedID      uuid.UUID
+	Content     sql.NullString
+	Guid        sql.NullString
+	ContentHash sql.NullString
+	PlainText   sql.NullString
+	Inserted    bool
+}
+
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (UpsertPostRow, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.Content,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.Guid,
+		arg.ContentHash,
+		arg.PlainText,
+	)
+	var i UpsertPostRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Content,
+		&i.Guid,
+		&i.ContentHash,
+		&i.PlainText,
+		&i.Inserted,
+	)
+	return i, err
+}
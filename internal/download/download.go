@@ -0,0 +1,137 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrTooLarge = errors.New("download exceeds size limit")
+	ErrStalled  = errors.New("download stalled")
+)
+
+// File downloads url to path, resuming from path + ".part" when a previous
+// run was interrupted. maxBytes <= 0 disables the size limit. The download
+// fails with ErrStalled when the server sends nothing for timeout, timeout
+// <= 0 waits forever. The size of the finished file is returned.
+func File(ctx context.Context, url, path string, maxBytes int64, timeout time.Duration) (int64, error) {
+	partPath := path + ".part"
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return 0, err
+	}
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// the timer is reset whenever data arrives, see stallReader
+	var timer *time.Timer
+	if timeout > 0 {
+		timer = time.AfterFunc(timeout, func() {
+			cancel(fmt.Errorf("%w: nothing received for %s", ErrStalled, timeout))
+		})
+		defer timer.Stop()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "gator")
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, stallError(ctx, err)
+	}
+	defer res.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// server ignored the range, start over
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the part file already holds the whole file
+		return offset, os.Rename(partPath, path)
+	default:
+		return 0, fmt.Errorf("unexpected status downloading %s: %s", url, res.Status)
+	}
+
+	if maxBytes > 0 && res.ContentLength > 0 && offset+res.ContentLength > maxBytes {
+		return 0, fmt.Errorf("%w: %d bytes", ErrTooLarge, offset+res.ContentLength)
+	}
+
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return 0, err
+	}
+
+	var body io.Reader = res.Body
+	if timer != nil {
+		body = &stallReader{reader: body, timer: timer, timeout: timeout}
+	}
+	if maxBytes > 0 {
+		// the extra byte tells a file of exactly maxBytes from a larger one
+		body = io.LimitReader(res.Body, maxBytes-offset+1)
+	}
+
+	written, err := io.Copy(file, body)
+	closeErr := file.Close()
+	if err != nil {
+		return offset + written, stallError(ctx, err)
+	}
+	if closeErr != nil {
+		return offset + written, closeErr
+	}
+
+	size := offset + written
+	if maxBytes > 0 && size > maxBytes {
+		os.Remove(partPath)
+		return 0, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, maxBytes)
+	}
+
+	return size, os.Rename(partPath, path)
+}
+
+// stallReader pushes the stall timer back on every read that returns data
+type stallReader struct {
+	reader  io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (r *stallReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
+}
+
+// stallError reports ErrStalled instead of the context error when the
+// stall timer cut the request off
+func stallError(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); errors.Is(cause, ErrStalled) {
+		return cause
+	}
+	return err
+}
@@ -0,0 +1,155 @@
+package rssfeed
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// AtomFeed and AtomEntry read xml:base, which relative links are resolved against
+type AtomFeed struct {
+	Base     string      `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	Title    AtomText    `xml:"title"`
+	Subtitle string      `xml:"subtitle"`
+	Links    []AtomLink  `xml:"link"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	Base      string     `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	ID        string     `xml:"id"`
+	Title     AtomText   `xml:"title"`
+	Links     []AtomLink `xml:"link"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Summary   AtomText   `xml:"summary"`
+	Content   AtomText   `xml:"content"`
+	Author    AtomPerson `xml:"author"`
+}
+
+// AtomText is a text construct, xhtml content is kept as markup
+type AtomText struct {
+	Type     string `xml:"type,attr"`
+	Text     string `xml:",chardata"`
+	InnerXML string `xml:",innerxml"`
+}
+
+func (t AtomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.InnerXML)
+	}
+
+	return strings.TrimSpace(t.Text)
+}
+
+// title renders a title construct the way RSS carries titles, as text
+// with entities, html and xhtml titles lose their tags
+func (t AtomText) title() string {
+	if t.Type == "html" || t.Type == "xhtml" {
+		return html.EscapeString(PlainText(t.String()))
+	}
+
+	return html.EscapeString(t.Text)
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// alternateLink returns the href of the rel="alternate" link,
+// a link without rel counts as alternate per RFC 4287
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+
+	return ""
+}
+
+// enclosureLinks collects rel="enclosure" links, Atom's equivalent of <enclosure>
+func enclosureLinks(links []AtomLink) []Enclosure {
+	var enclosures []Enclosure
+
+	for _, link := range links {
+		if link.Rel != "enclosure" || strings.TrimSpace(link.Href) == "" {
+			continue
+		}
+
+		length, _ := strconv.ParseInt(strings.TrimSpace(link.Length), 10, 64)
+
+		enclosures = append(enclosures, Enclosure{URL: strings.TrimSpace(link.Href), Type: link.Type, Length: length})
+	}
+
+	return enclosures
+}
+
+type atomParser struct{}
+
+func (atomParser) Name() string {
+	return "atom"
+}
+
+func (atomParser) Detect(contentType string, body []byte) bool {
+	root, err := rootElement(body)
+
+	return err == nil && root.Space == atomNamespace && root.Local == "feed"
+}
+
+func (atomParser) Parse(body []byte) (*Feed, error) {
+	var atomFeed AtomFeed
+
+	err := unmarshalXML(body, &atomFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	return atomFeed.toFeed(), nil
+}
+
+func (a *AtomFeed) toFeed() *Feed {
+	feed := Feed{
+		Title:       a.Title.title(),
+		Link:        alternateLink(a.Links),
+		Description: a.Subtitle,
+		Base:        a.Base,
+	}
+
+	for _, entry := range a.Entries {
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+
+		content := entry.Content.String()
+
+		description := entry.Summary.String()
+		if description == "" {
+			description = content
+		}
+
+		feed.Items = append(feed.Items, Item{
+			ID:          entry.ID,
+			Title:       entry.Title.title(),
+			Link:        alternateLink(entry.Links),
+			Description: description,
+			Content:     content,
+			PubDate:     pubDate,
+			Author:      entry.Author.Name,
+			Enclosures:  enclosureLinks(entry.Links),
+			Base:        entry.Base,
+		})
+	}
+
+	return &feed
+}
@@ -0,0 +1,152 @@
+package rssfeed
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var charmaps = map[string]*[128]rune{
+	"windows-1250": &windows1250,
+	"windows-1251": &windows1251,
+	"windows-1252": &windows1252,
+	"iso-8859-2":   &iso88592,
+	"iso-8859-5":   &iso88595,
+	"iso-8859-15":  &iso885915,
+	"koi8-r":       &koi8r,
+	"koi8-u":       &koi8u,
+}
+
+var charsetAliases = map[string]string{
+	"utf8":        "utf-8",
+	"us-ascii":    "utf-8",
+	"ascii":       "utf-8",
+	"latin1":      "iso-8859-1",
+	"iso8859-1":   "iso-8859-1",
+	"iso_8859-1":  "iso-8859-1",
+	"l1":          "iso-8859-1",
+	"cp1250":      "windows-1250",
+	"cp1251":      "windows-1251",
+	"cp1252":      "windows-1252",
+	"x-cp1251":    "windows-1251",
+	"latin2":      "iso-8859-2",
+	"iso8859-2":   "iso-8859-2",
+	"iso8859-5":   "iso-8859-5",
+	"latin9":      "iso-8859-15",
+	"iso8859-15":  "iso-8859-15",
+	"koi8r":       "koi8-r",
+	"unicode":     "utf-16",
+	"ucs-2":       "utf-16",
+	"iso-10646-1": "utf-16",
+}
+
+var prologEncoding = regexp.MustCompile(`^(\s*<\?xml[^>]*?encoding\s*=\s*["'])([^"']+)(["'])`)
+
+// normalizeCharset maps a charset label to the name used in charmaps
+func normalizeCharset(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if alias, ok := charsetAliases[label]; ok {
+		return alias
+	}
+
+	return label
+}
+
+// detectCharset picks the document charset: a byte order mark wins, then
+// the Content-Type charset parameter, then the XML declaration
+func detectCharset(contentType string, body []byte) string {
+	switch {
+	case bytes.HasPrefix(body, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8"
+	case bytes.HasPrefix(body, []byte{0xFE, 0xFF}), bytes.HasPrefix(body, []byte{0xFF, 0xFE}):
+		return "utf-16"
+	}
+
+	var declared string
+	if match := prologEncoding.FindSubmatch(body); match != nil {
+		declared = normalizeCharset(string(match[2]))
+	}
+
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		charset := normalizeCharset(params["charset"])
+
+		// servers often claim utf-8 for every document, trust the
+		// declaration when the bytes prove the header wrong
+		if charset == "utf-8" && declared != "" && !utf8.Valid(body) {
+			return declared
+		}
+
+		return charset
+	}
+
+	if declared != "" {
+		return declared
+	}
+
+	return "utf-8"
+}
+
+// toUTF8 transcodes the document to UTF-8 and rewrites its XML declaration
+// to match, so encoding/xml never needs a CharsetReader
+func toUTF8(contentType string, body []byte) ([]byte, string, error) {
+	charset := detectCharset(contentType, body)
+
+	var decoded []byte
+
+	switch charset {
+	case "utf-8":
+		decoded = bytes.TrimPrefix(body, []byte{0xEF, 0xBB, 0xBF})
+		if !utf8.Valid(decoded) {
+			decoded = bytes.ToValidUTF8(decoded, []byte("�"))
+		}
+	case "utf-16", "utf-16le", "utf-16be":
+		decoded = decodeUTF16(body, charset == "utf-16le")
+	case "iso-8859-1":
+		decoded = make([]byte, 0, len(body)*2)
+		for _, b := range body {
+			decoded = utf8.AppendRune(decoded, rune(b))
+		}
+	default:
+		table, ok := charmaps[charset]
+		if !ok {
+			return nil, charset, fmt.Errorf("%w: %w %q", ErrMalformed, errUnsupportedCharset, charset)
+		}
+
+		decoded = make([]byte, 0, len(body)*2)
+		for _, b := range body {
+			if b < 0x80 {
+				decoded = append(decoded, b)
+				continue
+			}
+			decoded = utf8.AppendRune(decoded, table[b-0x80])
+		}
+	}
+
+	return prologEncoding.ReplaceAll(decoded, []byte("${1}UTF-8${3}")), charset, nil
+}
+
+// decodeUTF16 converts UTF-16, the byte order mark overrides littleEndian
+func decodeUTF16(body []byte, littleEndian bool) []byte {
+	if bytes.HasPrefix(body, []byte{0xFF, 0xFE}) {
+		littleEndian = true
+		body = body[2:]
+	} else if bytes.HasPrefix(body, []byte{0xFE, 0xFF}) {
+		littleEndian = false
+		body = body[2:]
+	}
+
+	units := make([]uint16, 0, len(body)/2)
+	for i := 0; i+1 < len(body); i += 2 {
+		if littleEndian {
+			units = append(units, uint16(body[i])|uint16(body[i+1])<<8)
+		} else {
+			units = append(units, uint16(body[i])<<8|uint16(body[i+1]))
+		}
+	}
+
+	return []byte(string(utf16.Decode(units)))
+}
@@ -0,0 +1,169 @@
+package rssfeed
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrNoDate = errors.New("empty date string")
+
+// zoneOffsets resolves the zone names feeds use in RFC 822 dates, Go only
+// knows the abbreviations of the local zone and would treat the rest as UTC
+var zoneOffsets = map[string]string{
+	"UT":   "+0000",
+	"UTC":  "+0000",
+	"GMT":  "+0000",
+	"Z":    "+0000",
+	"EST":  "-0500",
+	"EDT":  "-0400",
+	"CST":  "-0600",
+	"CDT":  "-0500",
+	"MST":  "-0700",
+	"MDT":  "-0600",
+	"PST":  "-0800",
+	"PDT":  "-0700",
+	"AKST": "-0900",
+	"AKDT": "-0800",
+	"HST":  "-1000",
+	"BST":  "+0100",
+	"IST":  "+0530",
+	"WET":  "+0000",
+	"WEST": "+0100",
+	"CET":  "+0100",
+	"CEST": "+0200",
+	"MET":  "+0100",
+	"MEST": "+0200",
+	"EET":  "+0200",
+	"EEST": "+0300",
+	"MSK":  "+0300",
+	"JST":  "+0900",
+	"KST":  "+0900",
+	"HKT":  "+0800",
+	"SGT":  "+0800",
+	"AWST": "+0800",
+	"ACST": "+0930",
+	"AEST": "+1000",
+	"AEDT": "+1100",
+	"NZST": "+1200",
+	"NZDT": "+1300",
+}
+
+// monthNames maps localized month names and abbreviations, lowercased and
+// without a trailing dot, to the English abbreviation Go parses
+var monthNames = map[string]string{
+	// Russian, nominative and genitive
+	"январь": "Jan", "января": "Jan", "янв": "Jan",
+	"февраль": "Feb", "февраля": "Feb", "фев": "Feb",
+	"март": "Mar", "марта": "Mar", "мар": "Mar",
+	"апрель": "Apr", "апреля": "Apr", "апр": "Apr",
+	"май": "May", "мая": "May",
+	"июнь": "Jun", "июня": "Jun", "июн": "Jun",
+	"июль": "Jul", "июля": "Jul", "июл": "Jul",
+	"август": "Aug", "августа": "Aug", "авг": "Aug",
+	"сентябрь": "Sep", "сентября": "Sep", "сен": "Sep",
+	"октябрь": "Oct", "октября": "Oct", "окт": "Oct",
+	"ноябрь": "Nov", "ноября": "Nov", "ноя": "Nov",
+	"декабрь": "Dec", "декабря": "Dec", "дек": "Dec",
+	// German
+	"januar": "Jan", "jän": "Jan", "februar": "Feb", "märz": "Mar", "mär": "Mar", "mai": "May",
+	"juni": "Jun", "juli": "Jul", "okt": "Oct", "oktober": "Oct", "dez": "Dec", "dezember": "Dec",
+	// French
+	"janvier": "Jan", "janv": "Jan", "février": "Feb", "févr": "Feb", "fév": "Feb", "mars": "Mar",
+	"avril": "Apr", "avr": "Apr", "juin": "Jun", "juillet": "Jul", "juil": "Jul", "août": "Aug",
+	"septembre": "Sep", "sept": "Sep", "octobre": "Oct", "novembre": "Nov", "décembre": "Dec", "déc": "Dec",
+	// Spanish and Italian
+	"enero": "Jan", "ene": "Jan", "febrero": "Feb", "marzo": "Mar", "abril": "Apr", "abr": "Apr",
+	"mayo": "May", "junio": "Jun", "julio": "Jul", "agosto": "Aug", "ago": "Aug",
+	"septiembre": "Sep", "setiembre": "Sep", "octubre": "Oct", "noviembre": "Nov", "diciembre": "Dec", "dic": "Dec",
+	"gennaio": "Jan", "gen": "Jan", "febbraio": "Feb", "aprile": "Apr", "maggio": "May", "mag": "May",
+	"giugno": "Jun", "giu": "Jun", "luglio": "Jul", "lug": "Jul", "settembre": "Sep", "set": "Sep",
+	"ottobre": "Oct", "ott": "Oct", "dicembre": "Dec",
+}
+
+// leadingWeekday matches "Mon, " and its localized forms like "Пн, " or "Mo., "
+var leadingWeekday = regexp.MustCompile(`^[^\d\s,]+\.?,\s*`)
+
+// dateLayouts are tried in order after the date has been normalized,
+// layouts without a zone are interpreted in the caller's location
+var dateLayouts = []string{
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 -07:00",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04 -0700",
+	"2 January 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05",
+	"2 Jan 2006 15:04",
+	"2 Jan 06 15:04:05",
+	"2 Jan 2006",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"Jan 2, 2006 15:04:05 -0700",
+	"Jan 2, 2006 15:04:05",
+	"January 2, 2006 15:04",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"Mon Jan _2 15:04:05 -0700 2006", // date(1) output
+	"Mon Jan _2 15:04:05 2006",
+}
+
+// ParseRSSTime parses a feed date, naive timestamps are taken as UTC
+func ParseRSSTime(dateStr string) (time.Time, error) {
+	return ParseTime(dateStr, time.UTC)
+}
+
+// ParseTime parses RFC 822 dates (two digit years and named zones
+// included), ISO 8601 with offsets and fractional seconds, and dates with
+// localized month names. Dates without a zone are read in loc.
+func ParseTime(dateStr string, loc *time.Location) (time.Time, error) {
+	dateStr = strings.Join(strings.Fields(dateStr), " ")
+	if dateStr == "" {
+		return time.Time{}, ErrNoDate
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	normalized := normalizeDate(dateStr)
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, normalized, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
+
+// normalizeDate drops the weekday, translates month names and replaces
+// zone names with numeric offsets
+func normalizeDate(dateStr string) string {
+	dateStr = leadingWeekday.ReplaceAllString(dateStr, "")
+
+	words := strings.Split(dateStr, " ")
+	for i, word := range words {
+		key := strings.TrimSuffix(strings.ToLower(word), ".")
+		if month, ok := monthNames[key]; ok {
+			words[i] = month
+			continue
+		}
+
+		if offset, ok := zoneOffsets[strings.ToUpper(word)]; ok && i > 0 {
+			words[i] = offset
+		}
+	}
+
+	return strings.Join(words, " ")
+}
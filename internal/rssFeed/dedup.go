@@ -0,0 +1,94 @@
+package rssfeed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams are query parameters that differ between fetches of
+// the same article and must not affect deduplication
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"ref":     true,
+	"ref_src": true,
+}
+
+// NormalizeURL lowercases the scheme and host, drops the fragment, default
+// ports and tracking parameters, and sorts the remaining query so the same
+// article always produces the same string
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return raw
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Host = strings.TrimSuffix(parsed.Host, ":80")
+	parsed.Host = strings.TrimSuffix(parsed.Host, ":443")
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	query := parsed.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") || trackingParams[strings.ToLower(key)] {
+			query.Del(key)
+		}
+	}
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	parsed.RawQuery = strings.Join(parts, "&")
+
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+
+	return parsed.String()
+}
+
+// ContentHash fingerprints the visible text of the item
+func (i Item) ContentHash() string {
+	sum := sha256.Sum256([]byte(i.Title + "\x00" + i.Description + "\x00" + i.Content))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// DedupKey identifies the item within its feed: the guid when the
+// publisher provides one, else the normalized link, else the content hash.
+// The link is taken as published, posts stored before links were made
+// absolute keep their key.
+func (i Item) DedupKey() string {
+	if id := strings.TrimSpace(i.ID); id != "" {
+		return id
+	}
+
+	link := i.RawLink
+	if link == "" {
+		link = i.Link
+	}
+
+	if link != "" {
+		return NormalizeURL(link)
+	}
+
+	return "sha256:" + i.ContentHash()
+}
@@ -0,0 +1,149 @@
+package rssfeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrNoFeedFound is returned by Discover when a page links to no feed
+var ErrNoFeedFound = errors.New("no feed found")
+
+// commonFeedPaths are probed when a page does not advertise its feeds
+var commonFeedPaths = []string{"/feed", "/rss.xml", "/atom.xml", "/feed.xml", "/index.xml", "/rss"}
+
+// feedLinkTypes are the <link type> values that announce a feed
+var feedLinkTypes = map[string]bool{
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+	"application/rdf+xml":   true,
+	"application/rss+xml":   true,
+}
+
+var (
+	linkTagPattern   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	attributePattern = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+)`)
+)
+
+// Candidate is a feed found by Discover
+type Candidate struct {
+	URL    string
+	Title  string
+	Format string
+	Items  int
+}
+
+// Discover returns the feeds behind pageURL. A feed URL yields itself,
+// a web page yields the feeds it links to with <link rel="alternate">,
+// or those found at common paths when it links to none. Candidates that
+// moved permanently are returned under their new URL. Every request is
+// limited to timeout, DefaultTimeout when 0.
+func Discover(ctx context.Context, pageURL string, timeout time.Duration) ([]Candidate, error) {
+	res, body, permanentURL, err := get(ctx, pageURL, nil, DefaultMaxBodySize, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	feedURL := pageURL
+	if permanentURL != "" {
+		feedURL = permanentURL
+	}
+
+	feed, parseErr := Parse(res.Request.URL.String(), res.Header.Get("Content-Type"), body)
+	if parseErr == nil {
+		return []Candidate{{URL: feedURL, Title: feed.Title, Format: feed.Format, Items: len(feed.Items)}}, nil
+	}
+	// links are ASCII, a page in a charset we cannot decode is still scanned
+	if !errors.Is(parseErr, ErrNotAFeed) && !errors.Is(parseErr, errUnsupportedCharset) {
+		return nil, parseErr
+	}
+
+	candidates := probe(ctx, feedLinks(res.Request.URL, string(body)), timeout)
+	if len(candidates) == 0 {
+		candidates = probe(ctx, commonPaths(res.Request.URL), timeout)
+	}
+
+	if len(candidates) == 0 {
+		if errors.Is(parseErr, errUnsupportedCharset) {
+			return nil, parseErr
+		}
+		return nil, fmt.Errorf("%w at %s", ErrNoFeedFound, pageURL)
+	}
+
+	return candidates, nil
+}
+
+// feedLinks extracts the feed URLs a page advertises in its <link> tags
+func feedLinks(page *url.URL, body string) []string {
+	var links []string
+
+	for _, tag := range linkTagPattern.FindAllString(body, -1) {
+		attrs := make(map[string]string)
+		for _, match := range attributePattern.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(match[1])] = html.UnescapeString(strings.Trim(match[2], `"'`))
+		}
+
+		rel := strings.Fields(strings.ToLower(attrs["rel"]))
+		if !slices.Contains(rel, "alternate") || attrs["href"] == "" {
+			continue
+		}
+
+		linkType := strings.ToLower(strings.TrimSpace(strings.Split(attrs["type"], ";")[0]))
+		if !feedLinkTypes[linkType] {
+			continue
+		}
+
+		links = append(links, resolveURL(page, attrs["href"]))
+	}
+
+	return links
+}
+
+func commonPaths(page *url.URL) []string {
+	var paths []string
+
+	for _, path := range commonFeedPaths {
+		paths = append(paths, resolveURL(page, path))
+	}
+
+	return paths
+}
+
+// probe fetches every URL and keeps the ones that parse as feeds
+func probe(ctx context.Context, urls []string, timeout time.Duration) []Candidate {
+	var candidates []Candidate
+	seen := make(map[string]bool)
+
+	for _, candidateURL := range urls {
+		key := NormalizeURL(candidateURL)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		result, err := FetchFeed(ctx, candidateURL, FetchOptions{Timeout: timeout})
+		if err != nil || result.Feed == nil {
+			continue
+		}
+
+		if result.PermanentURL != "" {
+			candidateURL = result.PermanentURL
+		}
+
+		candidates = append(candidates, Candidate{
+			URL:    candidateURL,
+			Title:  result.Feed.Title,
+			Format: result.Feed.Format,
+			Items:  len(result.Feed.Items),
+		})
+	}
+
+	return candidates
+}
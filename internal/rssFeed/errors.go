@@ -0,0 +1,52 @@
+package rssfeed
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrHTTPStatus = errors.New("unexpected HTTP status")
+	ErrNotAFeed   = errors.New("document is not a feed")
+	ErrTooLarge   = errors.New("feed exceeds maximum size")
+	ErrMalformed  = errors.New("malformed feed")
+
+	// errUnsupportedCharset is wrapped together with ErrMalformed
+	errUnsupportedCharset = errors.New("unsupported charset")
+)
+
+// StatusError is returned for non 2xx responses, it matches ErrHTTPStatus
+// with errors.Is and keeps the code for callers that need it
+type StatusError struct {
+	StatusCode int
+	Status     string
+	// RetryAfter is the server's Retry-After, 0 when absent
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrHTTPStatus, e.Status)
+}
+
+func (e *StatusError) Is(target error) bool {
+	return target == ErrHTTPStatus
+}
+
+// BodyError is returned when a 2xx response could not be used as a feed,
+// because it was too large, could not be read or did not parse. It keeps
+// the status and size of the response and unwraps to the cause.
+type BodyError struct {
+	StatusCode int
+	// Bytes is the size of the body, as far as it was read
+	Bytes int64
+	Err   error
+}
+
+func (e *BodyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BodyError) Unwrap() error {
+	return e.Err
+}
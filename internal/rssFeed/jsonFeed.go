@@ -0,0 +1,122 @@
+package rssfeed
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"strings"
+)
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+}
+
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+type jsonFeedParser struct{}
+
+func (jsonFeedParser) Name() string {
+	return "json"
+}
+
+// jsonFeedVersion prefixes the version of every JSON Feed
+const jsonFeedVersion = "https://jsonfeed.org/version/"
+
+// Detect matches JSON documents carrying the jsonfeed.org version URL,
+// other JSON such as API errors or REST endpoints is not a feed
+func (jsonFeedParser) Detect(contentType string, body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return false
+	}
+
+	var header struct {
+		Version string `json:"version"`
+	}
+	err := json.Unmarshal(trimmed, &header)
+	if err != nil {
+		// a broken document served as a feed is reported as malformed
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		return mediaType == "application/feed+json"
+	}
+
+	return strings.HasPrefix(header.Version, jsonFeedVersion)
+}
+
+func (jsonFeedParser) Parse(body []byte) (*Feed, error) {
+	var jsonFeed JSONFeed
+
+	err := json.Unmarshal(body, &jsonFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonFeed.toFeed(), nil
+}
+
+func (j *JSONFeed) toFeed() *Feed {
+	feed := Feed{
+		Title:       j.Title,
+		Link:        j.HomePageURL,
+		Description: j.Description,
+	}
+
+	for _, item := range j.Items {
+		content := item.ContentHTML
+		if content == "" {
+			content = item.ContentText
+		}
+
+		description := item.Summary
+		if description == "" {
+			description = content
+		}
+
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+
+		link := item.URL
+		if link == "" {
+			link = item.ID
+		}
+
+		var enclosures []Enclosure
+		for _, attachment := range item.Attachments {
+			enclosures = append(enclosures, Enclosure{URL: attachment.URL, Type: attachment.MimeType, Length: attachment.SizeInBytes})
+		}
+
+		feed.Items = append(feed.Items, Item{
+			ID:          item.ID,
+			Title:       item.Title,
+			Link:        link,
+			Description: description,
+			Content:     content,
+			PubDate:     pubDate,
+			Enclosures:  enclosures,
+		})
+	}
+
+	return &feed
+}
@@ -0,0 +1,136 @@
+package rssfeed
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Feed is the format independent document every Parser produces
+type Feed struct {
+	Format      string
+	Encoding    string // charset the document was transcoded from
+	Title       string
+	Link        string
+	Description string
+	// Base is the xml:base of the document, if any
+	Base     string
+	Schedule Schedule
+	Items    []Item
+}
+
+type Item struct {
+	ID    string
+	Title string
+	Link  string
+	// RawLink is Link as published, before it was made absolute
+	RawLink     string
+	Description string
+	Content     string
+	// Text is the plain text rendition of Content, or of Description when
+	// the item has no content
+	Text       string
+	PubDate    string
+	Author     string
+	Subject    string
+	Enclosures []Enclosure
+	// Base is the xml:base of the item, relative to the feed's base
+	Base string
+}
+
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+	// Duration is in seconds, 0 when unknown
+	Duration int
+	Image    string
+}
+
+// Parser turns one document format into a Feed
+type Parser interface {
+	// Name identifies the format, e.g. "rss" or "atom"
+	Name() string
+	// Detect reports whether the document is in this parser's format
+	Detect(contentType string, body []byte) bool
+	Parse(body []byte) (*Feed, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = []Parser{jsonFeedParser{}, atomParser{}, rdfParser{}, rssParser{}}
+)
+
+// Register adds a parser for a custom format, parsers are tried in
+// registration order after the built-in ones
+func Register(parser Parser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry = append(registry, parser)
+}
+
+// Parse sniffs the document, hands it to the first parser that
+// recognizes it and returns the resulting feed. Relative URLs are
+// resolved against xml:base, the channel link and then feedURL.
+func Parse(feedURL, contentType string, body []byte) (*Feed, error) {
+	body, charset, err := toUTF8(contentType, body)
+	if err != nil {
+		return nil, err
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, parser := range registry {
+		if !parser.Detect(contentType, body) {
+			continue
+		}
+
+		feed, err := parser.Parse(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrMalformed, parser.Name(), err)
+		}
+
+		feed.Format = parser.Name()
+		feed.Encoding = charset
+		feed.Description = PlainText(feed.Description)
+		feed.Title = titleText(feed.Title)
+
+		base := feed.resolveBase(feedURL)
+
+		for i := range feed.Items {
+			itemBase := resolveReference(base, feed.Items[i].Base)
+			feed.Items[i].resolveLinks(itemBase)
+			sanitizeItem(&feed.Items[i], itemBase)
+		}
+
+		return feed, nil
+	}
+
+	return nil, fmt.Errorf("%w: unrecognized format (content type %q)", ErrNotAFeed, contentType)
+}
+
+// sanitizeItem decodes entities in the title and replaces the description
+// and content with their safe HTML renditions
+func sanitizeItem(item *Item, base *url.URL) {
+	var descriptionText, contentText string
+
+	item.Title = titleText(item.Title)
+	item.Description, descriptionText = sanitizeHTML(item.Description, base)
+	item.Content, contentText = sanitizeHTML(item.Content, base)
+
+	item.Text = contentText
+	if item.Text == "" {
+		item.Text = descriptionText
+	}
+}
+
+// titleText decodes the entities of a title and puts it on one line.
+// Titles are text, a <T> in them is kept, parsers of formats with HTML
+// titles strip the tags themselves and escape the result.
+func titleText(raw string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(raw)), " ")
+}
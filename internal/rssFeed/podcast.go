@@ -0,0 +1,94 @@
+package rssfeed
+
+import (
+	"strconv"
+	"strings"
+)
+
+type ItunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// MediaContent is a Media RSS <media:content> element
+type MediaContent struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Medium   string `xml:"medium,attr"`
+	FileSize string `xml:"fileSize,attr"`
+	Duration string `xml:"duration,attr"`
+}
+
+type MediaGroup struct {
+	Content []MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+// ParseDuration converts an itunes:duration value ("3600", "62:03" or
+// "1:02:03") to seconds, returning 0 when the value is unusable
+func ParseDuration(value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	seconds := 0
+	for _, part := range strings.Split(value, ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+
+		seconds = seconds*60 + int(n)
+	}
+
+	return seconds
+}
+
+// podcastEnclosures merges <enclosure> and Media RSS elements into one
+// list, skipping media:content entries that repeat an enclosure URL
+func podcastEnclosures(item RSSItem) []Enclosure {
+	duration := ParseDuration(item.ItunesDuration)
+	image := strings.TrimSpace(item.ItunesImage.Href)
+
+	var enclosures []Enclosure
+	seen := make(map[string]bool)
+
+	for _, enclosure := range item.Enclosures {
+		url := strings.TrimSpace(enclosure.URL)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		length, _ := strconv.ParseInt(strings.TrimSpace(enclosure.Length), 10, 64)
+
+		enclosures = append(enclosures, Enclosure{URL: url, Type: enclosure.Type, Length: length, Duration: duration, Image: image})
+	}
+
+	var mediaContent []MediaContent
+	mediaContent = append(mediaContent, item.MediaContent...)
+	mediaContent = append(mediaContent, item.MediaGroup.Content...)
+
+	for _, media := range mediaContent {
+		url := strings.TrimSpace(media.URL)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+
+		length, _ := strconv.ParseInt(strings.TrimSpace(media.FileSize), 10, 64)
+
+		mediaDuration := ParseDuration(media.Duration)
+		if mediaDuration == 0 {
+			mediaDuration = duration
+		}
+
+		mediaType := media.Type
+		if mediaType == "" {
+			mediaType = media.Medium
+		}
+
+		enclosures = append(enclosures, Enclosure{URL: url, Type: mediaType, Length: length, Duration: mediaDuration, Image: image})
+	}
+
+	return enclosures
+}
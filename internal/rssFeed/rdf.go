@@ -0,0 +1,86 @@
+package rssfeed
+
+const rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// RDFFeed is an RSS 1.0 document, items live next to the channel
+// under <rdf:RDF> instead of inside it
+type RDFFeed struct {
+	Base    string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	Channel struct {
+		Base        string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+
+		UpdatePeriod    string `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
+	} `xml:"channel"`
+	Item []RDFItem `xml:"item"`
+}
+
+type RDFItem struct {
+	Base        string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Subject     string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	Identifier  string `xml:"http://purl.org/dc/elements/1.1/ identifier"`
+	Content     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+}
+
+type rdfParser struct{}
+
+func (rdfParser) Name() string {
+	return "rdf"
+}
+
+func (rdfParser) Detect(contentType string, body []byte) bool {
+	root, err := rootElement(body)
+
+	return err == nil && root.Space == rdfNamespace && root.Local == "RDF"
+}
+
+func (rdfParser) Parse(body []byte) (*Feed, error) {
+	var rdfFeed RDFFeed
+
+	err := unmarshalXML(body, &rdfFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	return rdfFeed.toFeed(), nil
+}
+
+func (r *RDFFeed) toFeed() *Feed {
+	feed := Feed{
+		Title:       r.Channel.Title,
+		Link:        r.Channel.Link,
+		Description: r.Channel.Description,
+		Schedule:    newSchedule("", nil, nil, r.Channel.UpdatePeriod, r.Channel.UpdateFrequency),
+		Base:        joinBase(r.Base, r.Channel.Base),
+	}
+
+	for _, item := range r.Item {
+		id := item.Identifier
+		if id == "" {
+			id = item.About
+		}
+
+		feed.Items = append(feed.Items, Item{
+			ID:          id,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			Content:     item.Content,
+			PubDate:     item.Date,
+			Author:      item.Creator,
+			Subject:     item.Subject,
+			Base:        item.Base,
+		})
+	}
+
+	return &feed
+}
@@ -0,0 +1,99 @@
+package rssfeed
+
+import (
+	"net/url"
+	"strings"
+)
+
+// resolveBase makes the channel link absolute and returns the URL items
+// are resolved against: the feed's xml:base, else its channel link, else
+// the URL the feed was fetched from
+func (f *Feed) resolveBase(feedURL string) *url.URL {
+	document, err := url.Parse(strings.TrimSpace(feedURL))
+	if err != nil || !document.IsAbs() {
+		document = nil
+	}
+
+	if f.Base != "" {
+		base := resolveReference(document, f.Base)
+		f.Link = resolveURL(base, f.Link)
+
+		return base
+	}
+
+	f.Link = resolveURL(document, f.Link)
+
+	if link, err := url.Parse(f.Link); err == nil && link.IsAbs() {
+		return link
+	}
+
+	return document
+}
+
+// resolveLinks makes the item link and enclosure URLs absolute, the link
+// as published is kept in RawLink
+func (i *Item) resolveLinks(base *url.URL) {
+	i.RawLink = i.Link
+	i.Link = resolveURL(base, i.Link)
+
+	for e := range i.Enclosures {
+		i.Enclosures[e].URL = resolveURL(base, i.Enclosures[e].URL)
+		i.Enclosures[e].Image = resolveURL(base, i.Enclosures[e].Image)
+	}
+}
+
+// joinBase combines the xml:base of the document root with the one of
+// the channel inside it, either may be empty
+func joinBase(outer, inner string) string {
+	outer = strings.TrimSpace(outer)
+	inner = strings.TrimSpace(inner)
+	if outer == "" || inner == "" {
+		return outer + inner
+	}
+
+	base, err := url.Parse(outer)
+	if err != nil {
+		return inner
+	}
+
+	return resolveReference(base, inner).String()
+}
+
+// resolveReference resolves a possibly relative xml:base against base,
+// base is returned unchanged when ref is empty or unparsable
+func resolveReference(base *url.URL, ref string) *url.URL {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return base
+	}
+
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return base
+	}
+
+	if base == nil {
+		if !parsed.IsAbs() {
+			return nil
+		}
+		return parsed
+	}
+
+	return base.ResolveReference(parsed)
+}
+
+// resolveURL returns ref as an absolute URL string, ref is kept as is
+// when there is no base or it cannot be parsed
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || base == nil {
+		return ref
+	}
+
+	parsed, err := url.Parse(ref)
+	if err != nil || parsed.IsAbs() {
+		return ref
+	}
+
+	return base.ResolveReference(parsed).String()
+}
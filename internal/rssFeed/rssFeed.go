@@ -1,86 +1,304 @@
 package rssfeed
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
-	"html"
 	"io"
 	"net/http"
 	"time"
 )
 
 type RSSFeed struct {
+	Base    string `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
 	Channel struct {
+		Base        string    `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
 		Title       string    `xml:"title"`
 		Link        string    `xml:"link"`
 		Description string    `xml:"description"`
 		Item        []RSSItem `xml:"item"`
+
+		// refresh hints, see Schedule
+		TTL             string   `xml:"ttl"`
+		SkipHours       []string `xml:"skipHours>hour"`
+		SkipDays        []string `xml:"skipDays>day"`
+		UpdatePeriod    string   `xml:"http://purl.org/rss/1.0/modules/syndication/ updatePeriod"`
+		UpdateFrequency string   `xml:"http://purl.org/rss/1.0/modules/syndication/ updateFrequency"`
 	} `xml:"channel"`
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
-	Guid        string `xml:"guid"`
+	Base        string         `xml:"http://www.w3.org/XML/1998/namespace base,attr"`
+	Title       string         `xml:"title"`
+	Link        string         `xml:"link"`
+	Description string         `xml:"description"`
+	PubDate     string         `xml:"pubDate"`
+	Guid        string         `xml:"guid"`
+	Enclosures  []RSSEnclosure `xml:"enclosure"`
+
+	// content module, WordPress and Substack put the full body here
+	Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+
+	// Dublin Core module, used by RSS 1.0 and many RSS 2.0 feeds
+	Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Subject string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	DCDate  string `xml:"http://purl.org/dc/elements/1.1/ date"`
+
+	// iTunes and Media RSS modules used by podcasts
+	ItunesDuration string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	ItunesImage    ItunesImage    `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	MediaContent   []MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaGroup     MediaGroup     `xml:"http://search.yahoo.com/mrss/ group"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type rssParser struct{}
+
+func (rssParser) Name() string {
+	return "rss"
 }
 
-// Helper function to parse RSS time formats
-func ParseRSSTime(dateStr string) (time.Time, error) {
-	if dateStr == "" {
-		return time.Time{}, fmt.Errorf("empty date string")
+func (rssParser) Detect(contentType string, body []byte) bool {
+	root, err := rootElement(body)
+
+	return err == nil && root.Local == "rss"
+}
+
+func (rssParser) Parse(body []byte) (*Feed, error) {
+	var rssFeed RSSFeed
+
+	err := unmarshalXML(body, &rssFeed)
+	if err != nil {
+		return nil, err
 	}
 
-	// Common RSS date formats
-	layouts := []string{
-		time.RFC1123Z,                    // "Mon, 02 Jan 2006 15:04:05 -0700"
-		time.RFC1123,                     // "Mon, 02 Jan 2006 15:04:05 MST"
-		"Mon, 2 Jan 2006 15:04:05 -0700", // Single digit day
-		"2006-01-02T15:04:05Z",           // ISO 8601
-		"2006-01-02 15:04:05",            // Simple format
+	return rssFeed.toFeed(), nil
+}
+
+func (r *RSSFeed) toFeed() *Feed {
+	feed := Feed{
+		Title:       r.Channel.Title,
+		Link:        r.Channel.Link,
+		Description: r.Channel.Description,
+		Schedule:    newSchedule(r.Channel.TTL, r.Channel.SkipHours, r.Channel.SkipDays, r.Channel.UpdatePeriod, r.Channel.UpdateFrequency),
+		Base:        joinBase(r.Base, r.Channel.Base),
 	}
 
-	for _, layout := range layouts {
-		if t, err := time.Parse(layout, dateStr); err == nil {
-			return t, nil
+	for _, item := range r.Channel.Item {
+		pubDate := item.PubDate
+		if pubDate == "" {
+			pubDate = item.DCDate
 		}
+
+		feed.Items = append(feed.Items, Item{
+			ID:          item.Guid,
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			Content:     item.Content,
+			PubDate:     pubDate,
+			Author:      item.Creator,
+			Subject:     item.Subject,
+			Enclosures:  podcastEnclosures(item),
+			Base:        item.Base,
+		})
 	}
 
-	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+	return &feed
+}
+
+// DefaultMaxBodySize caps feed documents when FetchOptions.MaxBytes is unset
+const DefaultMaxBodySize = 10 << 20
+
+// DefaultTimeout limits a request, redirects and body included, when
+// FetchOptions.Timeout is unset
+const DefaultTimeout = 30 * time.Second
+
+// FetchOptions carries the validators from the previous fetch
+// so the server can answer 304 Not Modified
+type FetchOptions struct {
+	ETag         string
+	LastModified string
+	// MaxBytes limits the response body, DefaultMaxBodySize when 0
+	MaxBytes int64
+	// Timeout limits the whole request, DefaultTimeout when 0
+	Timeout time.Duration
 }
 
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+type FetchResult struct {
+	// Feed is nil when NotModified is set
+	Feed        *Feed
+	NotModified bool
+	StatusCode  int
+	// Bytes is the size of the response body
+	Bytes        int64
+	ETag         string
+	LastModified string
+	// PermanentURL is where the feed moved to when the request was
+	// answered with 301 or 308 redirects, empty otherwise
+	PermanentURL string
+	// MaxAge is the Cache-Control max-age of the response, RetryAfter
+	// its Retry-After, both 0 when absent
+	MaxAge     time.Duration
+	RetryAfter time.Duration
+}
+
+func FetchFeed(ctx context.Context, feedURL string, opts FetchOptions) (*FetchResult, error) {
+	header := make(http.Header)
+	if opts.ETag != "" {
+		header.Set("If-None-Match", opts.ETag)
+	}
+	if opts.LastModified != "" {
+		header.Set("If-Modified-Since", opts.LastModified)
+	}
+
+	maxBytes := opts.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodySize
+	}
+
+	res, body, permanentURL, err := get(ctx, feedURL, header, maxBytes, opts.Timeout)
 	if err != nil {
 		return nil, err
 	}
+
+	result := FetchResult{
+		StatusCode:   res.StatusCode,
+		PermanentURL: permanentURL,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		MaxAge:       parseMaxAge(res.Header.Get("Cache-Control")),
+		RetryAfter:   parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+	}
+
+	if res.StatusCode == http.StatusNotModified {
+		// a 304 may omit the validators, keep the ones we sent
+		if result.ETag == "" {
+			result.ETag = opts.ETag
+		}
+		if result.LastModified == "" {
+			result.LastModified = opts.LastModified
+		}
+
+		result.NotModified = true
+		return &result, nil
+	}
+
+	result.Bytes = int64(len(body))
+
+	result.Feed, err = Parse(res.Request.URL.String(), res.Header.Get("Content-Type"), body)
+	if err != nil {
+		return nil, &BodyError{StatusCode: res.StatusCode, Bytes: result.Bytes, Err: err}
+	}
+
+	return &result, nil
+}
+
+// get requests rawURL with the given extra headers and reads at most
+// maxBytes of the body, giving up after timeout or DefaultTimeout when 0. A 304 is returned without a body, other statuses
+// outside 2xx fail with a StatusError and bodies that are too large or
+// cannot be read with a BodyError. permanentURL is where permanent
+// redirects led, see redirectTrackingClient.
+func get(ctx context.Context, rawURL string, header http.Header, maxBytes int64, timeout time.Duration) (res *http.Response, body []byte, permanentURL string, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
 	req.Header.Set("User-Agent", "gator")
 
-	client := &http.Client{}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
 
-	res, err := client.Do(req)
+	res, err = redirectTrackingClient(&permanentURL, timeout).Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		return res, nil, permanentURL, nil
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		retryAfter := parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+		return nil, nil, "", &StatusError{StatusCode: res.StatusCode, Status: res.Status, RetryAfter: retryAfter}
 	}
 
-	body, err := io.ReadAll(res.Body)
+	if res.ContentLength > maxBytes {
+		return nil, nil, "", &BodyError{StatusCode: res.StatusCode, Bytes: res.ContentLength, Err: fmt.Errorf("%w: %d bytes", ErrTooLarge, res.ContentLength)}
+	}
+
+	// read one byte past the limit so an oversized body is detected
+	body, err = io.ReadAll(io.LimitReader(res.Body, maxBytes+1))
 	if err != nil {
-		return nil, err
+		return nil, nil, "", &BodyError{StatusCode: res.StatusCode, Bytes: int64(len(body)), Err: err}
 	}
 
-	var rssFeed RSSFeed
+	if int64(len(body)) > maxBytes {
+		return nil, nil, "", &BodyError{StatusCode: res.StatusCode, Bytes: int64(len(body)), Err: fmt.Errorf("%w: more than %d bytes", ErrTooLarge, maxBytes)}
+	}
 
-	xml.Unmarshal(body, &rssFeed)
+	return res, body, permanentURL, nil
+}
+
+// redirectTrackingClient follows redirects and records in permanentURL
+// where the leading run of permanent redirects ends, a temporary one
+// later in the chain does not move the feed
+func redirectTrackingClient(permanentURL *string, timeout time.Duration) *http.Client {
+	temporary := false
 
-	rssFeed.Channel.Description = html.UnescapeString(rssFeed.Channel.Description)
-	rssFeed.Channel.Title = html.UnescapeString(rssFeed.Channel.Title)
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
 
-	for _, item := range rssFeed.Channel.Item {
-		item.Description = html.UnescapeString(item.Description)
-		item.Title = html.UnescapeString(item.Title)
+			code := req.Response.StatusCode
+			if !temporary && (code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect) {
+				*permanentURL = req.URL.String()
+			} else {
+				temporary = true
+			}
+
+			return nil
+		},
 	}
+}
 
-	return &rssFeed, nil
+// unmarshalXML decodes a feed document. HTML entities such as &nbsp; are
+// not defined in XML but common in feeds, they are decoded instead of
+// failing the whole document.
+func unmarshalXML(body []byte, v any) error {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.Entity = xml.HTMLEntity
+
+	return decoder.Decode(v)
+}
+
+// rootElement returns the name of the first element in the document
+func rootElement(body []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, fmt.Errorf("failed to find root element: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
 }
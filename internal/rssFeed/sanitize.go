@@ -0,0 +1,359 @@
+package rssfeed
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// markupPattern finds comments, CDATA sections, doctypes, processing
+	// instructions and tags, a < that starts none of them is text
+	markupPattern = regexp.MustCompile(`(?s)<!--.*?(?:-->|$)|<!\[CDATA\[.*?(?:\]\]>|$)|<[!?][^>]*>|</?[a-zA-Z](?:[^>"']|"[^"]*"|'[^']*')*>`)
+	tagPattern    = regexp.MustCompile(`(?s)^<(/?)([a-zA-Z][a-zA-Z0-9:-]*)(.*)>$`)
+	// tagAttributePattern reads name=value pairs, the value is optional
+	tagAttributePattern = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+))?`)
+)
+
+// allowedTags lists the tags kept in safe HTML and the attributes each may carry
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"audio":      {"src", "controls"},
+	"b":          nil,
+	"blockquote": {"cite"},
+	"br":         nil,
+	"caption":    nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        nil,
+	"kbd":        nil,
+	"li":         nil,
+	"ol":         nil,
+	"p":          nil,
+	"pre":        nil,
+	"q":          {"cite"},
+	"s":          nil,
+	"small":      nil,
+	"source":     {"src", "type"},
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+	"video":      {"src", "controls", "poster"},
+}
+
+// droppedTags are removed together with everything inside them
+var droppedTags = map[string]bool{
+	"button":   true,
+	"embed":    true,
+	"form":     true,
+	"head":     true,
+	"iframe":   true,
+	"math":     true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"select":   true,
+	"style":    true,
+	"svg":      true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+}
+
+// rawTextTags hold text that is not markup, they are skipped up to their end tag
+var rawTextTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"title":    true,
+	"xmp":      true,
+}
+
+var voidTags = map[string]bool{
+	"br":     true,
+	"hr":     true,
+	"img":    true,
+	"source": true,
+	"wbr":    true,
+}
+
+// blockTags start a new line in the plain text rendition
+var blockTags = map[string]bool{
+	"blockquote": true,
+	"br":         true,
+	"div":        true,
+	"dd":         true,
+	"dt":         true,
+	"figcaption": true,
+	"h1":         true,
+	"h2":         true,
+	"h3":         true,
+	"h4":         true,
+	"h5":         true,
+	"h6":         true,
+	"hr":         true,
+	"li":         true,
+	"p":          true,
+	"pre":        true,
+	"tr":         true,
+}
+
+var urlAttributes = map[string]bool{
+	"cite":   true,
+	"href":   true,
+	"poster": true,
+	"src":    true,
+}
+
+// SanitizeHTML returns a safe HTML rendition of an item body and its plain
+// text. Scripts, styles, frames, event handlers, javascript: links and 1x1
+// tracking images are removed.
+func SanitizeHTML(raw string) (safeHTML string, text string) {
+	return sanitizeHTML(raw, nil)
+}
+
+// sanitizeHTML is SanitizeHTML that also makes URL attributes absolute
+// against base, when it is not nil. Feeds carry broken markup, so tags
+// are read one by one and stray end tags are skipped, the elements left
+// open are closed at the end.
+func sanitizeHTML(raw string, base *url.URL) (safeHTML string, text string) {
+	if strings.TrimSpace(raw) == "" {
+		return "", ""
+	}
+
+	var safe, plain strings.Builder
+	// allowed elements written to safe and not closed yet
+	var open []string
+	// name of the dropped element being skipped and how deep it nests
+	dropName, dropDepth := "", 0
+
+	writeText := func(data string) {
+		if dropDepth > 0 {
+			return
+		}
+		data = html.UnescapeString(data)
+		safe.WriteString(html.EscapeString(data))
+		plain.WriteString(data)
+	}
+
+	for len(raw) > 0 {
+		loc := markupPattern.FindStringIndex(raw)
+		if loc == nil {
+			writeText(raw)
+			break
+		}
+
+		writeText(raw[:loc[0]])
+		markup := raw[loc[0]:loc[1]]
+		raw = raw[loc[1]:]
+
+		if cdata, ok := strings.CutPrefix(markup, "<![CDATA["); ok {
+			writeText(html.EscapeString(strings.TrimSuffix(cdata, "]]>")))
+			continue
+		}
+
+		match := tagPattern.FindStringSubmatch(markup)
+		if match == nil {
+			// comments, doctypes and processing instructions
+			continue
+		}
+
+		name := strings.ToLower(match[2])
+
+		if match[1] == "/" {
+			if dropDepth > 0 {
+				if name == dropName {
+					dropDepth--
+				}
+				continue
+			}
+
+			if blockTags[name] {
+				plain.WriteString("\n")
+			}
+
+			// an end tag without a start tag is dropped, one that skips
+			// over open elements closes them too
+			if i := slices.Index(open, name); i >= 0 {
+				for j := len(open) - 1; j >= i; j-- {
+					safe.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+			}
+			continue
+		}
+
+		if rawTextTags[name] {
+			// the content of scripts and styles is not markup, skip to the end tag
+			end := strings.Index(strings.ToLower(raw), "</"+name)
+			if end < 0 {
+				break
+			}
+			raw = raw[end:]
+			if close := strings.IndexByte(raw, '>'); close >= 0 {
+				raw = raw[close+1:]
+			} else {
+				raw = ""
+			}
+			continue
+		}
+
+		if dropDepth > 0 || droppedTags[name] {
+			if dropDepth == 0 {
+				dropName = name
+			}
+			if name == dropName && !voidTags[name] && !strings.HasSuffix(match[3], "/") {
+				dropDepth++
+			}
+			continue
+		}
+
+		if blockTags[name] {
+			plain.WriteString("\n")
+		}
+
+		attrs := parseAttributes(match[3])
+
+		allowed, ok := allowedTags[name]
+		if !ok || (name == "img" && isTrackingPixel(attrs)) {
+			continue
+		}
+
+		safe.WriteString("<" + name)
+		for _, attr := range attrs {
+			if !slices.Contains(allowed, attr.name) {
+				continue
+			}
+			value := attr.value
+			if urlAttributes[attr.name] {
+				if !isSafeURL(value) {
+					continue
+				}
+				value = resolveURL(base, value)
+			}
+			safe.WriteString(" " + attr.name + `="` + html.EscapeString(value) + `"`)
+		}
+		safe.WriteString(">")
+
+		if !voidTags[name] {
+			open = append(open, name)
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		safe.WriteString("</" + open[i] + ">")
+	}
+
+	return strings.TrimSpace(safe.String()), collapseWhitespace(plain.String())
+}
+
+// htmlAttribute is one attribute of a tag, the value with entities decoded
+type htmlAttribute struct {
+	name  string
+	value string
+}
+
+// parseAttributes reads the attributes of a tag, quoted or not
+func parseAttributes(raw string) []htmlAttribute {
+	var attrs []htmlAttribute
+
+	for _, match := range tagAttributePattern.FindAllStringSubmatch(raw, -1) {
+		value := match[2]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		attrs = append(attrs, htmlAttribute{name: strings.ToLower(match[1]), value: html.UnescapeString(value)})
+	}
+
+	return attrs
+}
+
+// PlainText strips all markup and decodes entities, for titles and
+// other fields that should never carry HTML
+func PlainText(raw string) string {
+	if !strings.ContainsAny(raw, "<&") {
+		return collapseWhitespace(raw)
+	}
+
+	_, text := SanitizeHTML(raw)
+
+	return text
+}
+
+// collapseWhitespace squeezes runs of spaces inside lines and drops empty lines
+func collapseWhitespace(text string) string {
+	var lines []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isTrackingPixel spots the invisible 1x1 images used for read tracking
+func isTrackingPixel(attrs []htmlAttribute) bool {
+	var width, height, style string
+
+	for _, attr := range attrs {
+		switch attr.name {
+		case "width":
+			width = strings.TrimSuffix(strings.TrimSpace(attr.value), "px")
+		case "height":
+			height = strings.TrimSuffix(strings.TrimSpace(attr.value), "px")
+		case "style":
+			style = strings.ToLower(strings.ReplaceAll(attr.value, " ", ""))
+		}
+	}
+
+	tiny := func(size string) bool { return size == "0" || size == "1" }
+
+	return (tiny(width) && tiny(height)) || strings.Contains(style, "display:none")
+}
+
+// isSafeURL allows http(s), mailto and relative URLs
+func isSafeURL(raw string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
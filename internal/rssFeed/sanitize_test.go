@@ -0,0 +1,158 @@
+package rssfeed
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		safe string
+		text string
+	}{
+		{
+			name: "formatting",
+			raw:  `<p>Hello <b>world</b></p><p>again</p>`,
+			safe: `<p>Hello <b>world</b></p><p>again</p>`,
+			text: "Hello world\nagain",
+		},
+		{
+			name: "stray end tag",
+			raw:  `</p>stray end<br>line`,
+			safe: `stray end<br>line`,
+			text: "stray end\nline",
+		},
+		{
+			name: "stray end tag in an element",
+			raw:  `<div>text</span></div>`,
+			safe: `<div>text</div>`,
+			text: "text",
+		},
+		{
+			name: "unclosed elements",
+			raw:  `<p>truncated <em>excerpt`,
+			safe: `<p>truncated <em>excerpt</em></p>`,
+			text: "truncated excerpt",
+		},
+		{
+			name: "misnested elements",
+			raw:  `<b><i>text</b></i>`,
+			safe: `<b><i>text</i></b>`,
+			text: "text",
+		},
+		{
+			name: "unquoted event handler",
+			raw:  `<img src=x onerror=alert(1)>`,
+			safe: `<img src="x">`,
+			text: "",
+		},
+		{
+			name: "script",
+			raw:  `<script>if (a<b) alert("</p>")</script>after`,
+			safe: `after`,
+			text: "after",
+		},
+		{
+			name: "dropped element",
+			raw:  `<iframe src="https://example.com"><p>inside</p></iframe>after`,
+			safe: `after`,
+			text: "after",
+		},
+		{
+			name: "javascript link",
+			raw:  `<a href="javascript:alert(1)" onclick="x()">link</a>`,
+			safe: `<a>link</a>`,
+			text: "link",
+		},
+		{
+			name: "tracking pixel",
+			raw:  `text<img src="https://example.com/p.gif" width="1" height="1">`,
+			safe: `text`,
+			text: "text",
+		},
+		{
+			name: "less than sign",
+			raw:  `a < b && c`,
+			safe: `a &lt; b &amp;&amp; c`,
+			text: "a < b && c",
+		},
+		{
+			name: "entities",
+			raw:  `Option&lt;T&gt; &amp; more&nbsp;text`,
+			safe: "Option&lt;T&gt; &amp; more\u00a0text",
+			text: "Option<T> & more text",
+		},
+		{
+			name: "comment",
+			raw:  `before<!-- <p>hidden</p> -->after`,
+			safe: `beforeafter`,
+			text: "beforeafter",
+		},
+		{
+			name: "quoted greater than sign",
+			raw:  `<a href="/a" title="a > b">link</a>`,
+			safe: `<a href="/a" title="a &gt; b">link</a>`,
+			text: "link",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			safe, text := SanitizeHTML(test.raw)
+			if safe != test.safe {
+				t.Errorf("safe HTML = %q, want %q", safe, test.safe)
+			}
+			if text != test.text {
+				t.Errorf("text = %q, want %q", text, test.text)
+			}
+		})
+	}
+}
+
+func TestParseTitles(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		title string
+	}{
+		{
+			name:  "rss",
+			body:  `<rss><channel><item><title>Understanding Option&lt;T&gt; in Rust</title></item></channel></rss>`,
+			title: "Understanding Option<T> in Rust",
+		},
+		{
+			name:  "rss with escaped entities",
+			body:  `<rss><channel><item><title>std::vector&amp;lt;int&amp;gt; tips</title></item></channel></rss>`,
+			title: "std::vector<int> tips",
+		},
+		{
+			name:  "atom text",
+			body:  `<feed xmlns="http://www.w3.org/2005/Atom"><entry><title type="text">Option&lt;T&gt;</title></entry></feed>`,
+			title: "Option<T>",
+		},
+		{
+			name:  "atom html",
+			body:  `<feed xmlns="http://www.w3.org/2005/Atom"><entry><title type="html">&lt;b&gt;Option&lt;/b&gt;&amp;lt;T&amp;gt;</title></entry></feed>`,
+			title: "Option<T>",
+		},
+		{
+			name:  "atom xhtml",
+			body:  `<feed xmlns="http://www.w3.org/2005/Atom"><entry><title type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><b>Bold</b> title</div></title></entry></feed>`,
+			title: "Bold title",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			feed, err := Parse("https://example.com/feed", "", []byte(test.body))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(feed.Items) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Items))
+			}
+			if feed.Items[0].Title != test.title {
+				t.Errorf("title = %q, want %q", feed.Items[0].Title, test.title)
+			}
+		})
+	}
+}
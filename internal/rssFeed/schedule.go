@@ -0,0 +1,134 @@
+package rssfeed
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule holds the publisher's hints on how often to fetch the feed,
+// zero values mean no hint
+type Schedule struct {
+	// TTL is the RSS <ttl>, how long the feed may be cached
+	TTL time.Duration
+	// UpdatePeriod is how often the feed changes per sy:updatePeriod
+	// and sy:updateFrequency
+	UpdatePeriod time.Duration
+	// SkipHours and SkipDays are the UTC hours and days the publisher
+	// asks not to be fetched in
+	SkipHours []int
+	SkipDays  []time.Weekday
+}
+
+// Skips reports whether t falls in a skipped hour or day
+func (s Schedule) Skips(t time.Time) bool {
+	t = t.UTC()
+
+	for _, hour := range s.SkipHours {
+		if t.Hour() == hour {
+			return true
+		}
+	}
+
+	for _, day := range s.SkipDays {
+		if t.Weekday() == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+var syndicationPeriods = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+	"yearly":  365 * 24 * time.Hour,
+}
+
+// newSchedule reads the RSS ttl, skipHours and skipDays elements and
+// the syndication module, ignoring values it cannot make sense of
+func newSchedule(ttl string, skipHours []string, skipDays []string, updatePeriod, updateFrequency string) Schedule {
+	var schedule Schedule
+
+	if minutes, err := strconv.Atoi(strings.TrimSpace(ttl)); err == nil && minutes > 0 {
+		schedule.TTL = time.Duration(minutes) * time.Minute
+	}
+
+	for _, value := range skipHours {
+		hour, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || hour < 0 || hour > 24 {
+			continue
+		}
+		// some publishers count hours 1-24
+		schedule.SkipHours = append(schedule.SkipHours, hour%24)
+	}
+
+	for _, value := range skipDays {
+		if day, ok := weekdays[strings.ToLower(strings.TrimSpace(value))]; ok {
+			schedule.SkipDays = append(schedule.SkipDays, day)
+		}
+	}
+
+	if period, ok := syndicationPeriods[strings.ToLower(strings.TrimSpace(updatePeriod))]; ok {
+		frequency, err := strconv.Atoi(strings.TrimSpace(updateFrequency))
+		if err != nil || frequency < 1 {
+			frequency = 1
+		}
+		schedule.UpdatePeriod = period / time.Duration(frequency)
+	}
+
+	return schedule
+}
+
+// parseMaxAge returns the max-age of a Cache-Control header, 0 when
+// it is missing or the response must not be cached
+func parseMaxAge(cacheControl string) time.Duration {
+	var maxAge time.Duration
+
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, _ := strings.Cut(strings.ToLower(strings.TrimSpace(directive)), "=")
+
+		switch name {
+		case "no-cache", "no-store":
+			return 0
+		case "max-age":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+
+	return maxAge
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as a date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+
+	return 0
+}
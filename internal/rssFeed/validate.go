@@ -0,0 +1,119 @@
+package rssfeed
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Problem is an issue Validate found, Item is the index of the offending
+// item or -1 for the feed itself
+type Problem struct {
+	Item    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Item < 0 {
+		return "feed: " + p.Message
+	}
+
+	return fmt.Sprintf("item %d: %s", p.Item+1, p.Message)
+}
+
+// Validate reports what would keep a parsed feed from being stored
+// cleanly: missing or unparsable dates, links and guids, duplicate guids
+// and text damaged by a wrong charset
+func Validate(feed *Feed) []Problem {
+	var problems []Problem
+
+	feedProblem := func(format string, args ...any) {
+		problems = append(problems, Problem{Item: -1, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if feed.Title == "" {
+		feedProblem("missing title")
+	}
+	if feed.Link == "" {
+		feedProblem("missing link")
+	}
+	if len(feed.Items) == 0 {
+		feedProblem("no items")
+	}
+	if hasReplacementChars(feed.Title, feed.Description) {
+		feedProblem("text contains invalid %s characters", feed.Encoding)
+	}
+
+	guids := make(map[string]int)
+
+	for i, item := range feed.Items {
+		itemProblem := func(format string, args ...any) {
+			problems = append(problems, Problem{Item: i, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if item.Title == "" && item.Description == "" {
+			itemProblem("missing both title and description")
+		}
+
+		publishedAt, err := ParseRSSTime(item.PubDate)
+		switch {
+		case errors.Is(err, ErrNoDate):
+			itemProblem("missing date, the fetch time will be used")
+		case err != nil:
+			itemProblem("unparsable date %q", item.PubDate)
+		case publishedAt.After(time.Now().Add(24 * time.Hour)):
+			itemProblem("date %s is in the future", publishedAt.Format(time.RFC3339))
+		}
+
+		// Link was made absolute by Parse, RawLink is what the feed says
+		if item.Link == "" {
+			itemProblem("missing link")
+		} else if link, err := url.Parse(item.Link); err != nil || !link.IsAbs() {
+			itemProblem("link %q is not an absolute URL", item.Link)
+		} else if raw := strings.TrimSpace(item.RawLink); raw != "" && raw != item.Link {
+			itemProblem("relative link %q, resolved to %s", raw, item.Link)
+		}
+
+		guid := strings.TrimSpace(item.ID)
+		if guid == "" {
+			itemProblem("missing guid, deduplicated by %s", dedupSource(item))
+		} else if first, ok := guids[guid]; ok {
+			itemProblem("guid %q already used by item %d", guid, first+1)
+		} else {
+			guids[guid] = i
+		}
+
+		for _, enclosure := range item.Enclosures {
+			if link, err := url.Parse(enclosure.URL); err != nil || !link.IsAbs() {
+				itemProblem("enclosure URL %q is not an absolute URL", enclosure.URL)
+			}
+		}
+
+		if hasReplacementChars(item.Title, item.Text) {
+			itemProblem("text contains invalid %s characters", feed.Encoding)
+		}
+	}
+
+	return problems
+}
+
+func dedupSource(item Item) string {
+	if item.Link != "" {
+		return "link"
+	}
+
+	return "content hash"
+}
+
+// hasReplacementChars spots text that failed to decode in the detected charset
+func hasReplacementChars(values ...string) bool {
+	for _, value := range values {
+		if strings.ContainsRune(value, '�') {
+			return true
+		}
+	}
+
+	return false
+}
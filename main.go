@@ -30,10 +30,16 @@ func main() {
 	commands.Register("agg", command.HandlerAgg)
 	commands.Register("addfeed", command.MiddlewareLoggedIn(command.HandlerAddFeed))
 	commands.Register("feeds", command.HandlerGetFeeds)
+	commands.Register("feed", command.HandlerFeed)
 	commands.Register("follow", command.MiddlewareLoggedIn(command.HandlerFollow))
 	commands.Register("following", command.MiddlewareLoggedIn(command.HandlerFollowing))
 	commands.Register("unfollow", command.MiddlewareLoggedIn(command.HandlerUnfollow))
 	commands.Register("browse", command.MiddlewareLoggedIn(command.HandlerBrowse))
+	commands.Register("read", command.HandlerRead)
+	commands.Register("history", command.HandlerHistory)
+	commands.Register("download", command.MiddlewareLoggedIn(command.HandlerDownload))
+	commands.Register("validate", command.HandlerValidate)
+	commands.Register("fetchlog", command.HandlerFetchLog)
 
 	db, err := sql.Open("postgres", foundConfig.DBUrl)
 
@@ -44,6 +50,7 @@ func main() {
 
 	dbQueries := database.New(db)
 	state.Db = dbQueries
+	state.Conn = db
 
 	if len(os.Args) < 2 {
 		fmt.Print("Not enough arguments\n")
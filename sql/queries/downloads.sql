@@ -0,0 +1,51 @@
+-- name: GetEpisodesForUser :many
+SELECT
+    e.id,
+    e.url,
+    e.mime_type,
+    p.id AS post_id,
+    p.title AS post_title,
+    p.published_at,
+    f.id AS feed_id,
+    f.name AS feed_name
+FROM enclosures e
+JOIN posts p ON e.post_id = p.id
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON ff.feed_id = f.id
+WHERE ff.user_id = $1
+ORDER BY f.id, p.published_at DESC, p.id;
+
+-- name: GetDownloadByEnclosure :one
+SELECT * FROM downloads
+WHERE downloads.enclosure_id = $1;
+
+-- name: GetDownloadsForFeed :many
+SELECT * FROM downloads
+WHERE downloads.feed_id = $1;
+
+-- name: UpsertDownload :one
+INSERT INTO downloads (enclosure_id, feed_id, path, status)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (enclosure_id) DO UPDATE
+SET path = EXCLUDED.path, status = EXCLUDED.status, updated_at = NOW()
+RETURNING *;
+
+-- name: UpdateDownloadStatus :exec
+UPDATE downloads
+SET status = $2, bytes = $3, error = $4, updated_at = NOW()
+WHERE downloads.id = $1;
+
+-- name: MoveDownloads :exec
+UPDATE downloads
+SET feed_id = sqlc.arg(to_feed_id), updated_at = NOW()
+WHERE downloads.feed_id = sqlc.arg(from_feed_id)
+AND downloads.enclosure_id IN (
+    SELECT e.id FROM enclosures e
+    JOIN posts p ON e.post_id = p.id
+    WHERE p.feed_id = sqlc.arg(to_feed_id)
+);
@@ -0,0 +1,21 @@
+-- name: GetEnclosuresForPost :many
+SELECT * FROM enclosures
+WHERE enclosures.post_id = $1
+ORDER BY created_at;
+
+-- name: UpsertEnclosure :one
+INSERT INTO enclosures (post_id, url, mime_type, length, duration_seconds, image_url)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+ON CONFLICT (post_id, url) DO UPDATE
+SET mime_type = EXCLUDED.mime_type,
+    length = EXCLUDED.length,
+    duration_seconds = EXCLUDED.duration_seconds,
+    image_url = EXCLUDED.image_url
+RETURNING *;
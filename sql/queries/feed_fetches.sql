@@ -0,0 +1,37 @@
+-- name: CreateFeedFetch :one
+INSERT INTO feed_fetches (feed_id, started_at, finished_at, duration_ms, http_status, bytes, not_modified, error, items_inserted, items_updated, items_duplicate, items_rejected, rejections, moved_to)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    $13,
+    $14
+)
+RETURNING *;
+
+-- name: GetFeedFetches :many
+SELECT ff.*, f.name AS feed_name FROM feed_fetches ff
+JOIN feeds f ON ff.feed_id = f.id
+ORDER BY ff.started_at DESC
+LIMIT $1;
+
+-- name: GetFeedFetchesForFeed :many
+SELECT ff.*, f.name AS feed_name FROM feed_fetches ff
+JOIN feeds f ON ff.feed_id = f.id
+WHERE f.url = $1 OR f.name = $1
+ORDER BY ff.started_at DESC
+LIMIT $2;
+
+-- name: MoveFeedFetches :exec
+UPDATE feed_fetches
+SET feed_id = sqlc.arg(to_feed_id)
+WHERE feed_fetches.feed_id = sqlc.arg(from_feed_id);
@@ -32,4 +32,10 @@ WHERE feed_follows.user_id = $1
 AND feed_id = (
     SELECT id FROM feeds 
     WHERE feeds.url = $2
-);
\ No newline at end of file
+);
+
+-- name: MoveFeedFollows :exec
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+SELECT gen_random_uuid(), ff.created_at, NOW(), ff.user_id, sqlc.arg(to_feed_id) FROM feed_follows ff
+WHERE ff.feed_id = sqlc.arg(from_feed_id)
+ON CONFLICT (user_id, feed_id) DO NOTHING;
@@ -22,7 +22,84 @@ UPDATE feeds
 SET last_fetched_at = NOW(), updated_at = NOW()
 WHERE feeds.id = $1;
 
--- name: GetNextFeedToFetch :one
+-- name: ClaimFeedsToFetch :many
+UPDATE feeds
+SET lease_owner = sqlc.arg(lease_owner), lease_expires_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::float8)
+WHERE feeds.id IN (
+    SELECT id FROM feeds
+    WHERE (feeds.next_fetch_at IS NULL OR feeds.next_fetch_at <= NOW())
+      AND (feeds.lease_expires_at IS NULL OR feeds.lease_expires_at < NOW())
+      AND feeds.disabled_at IS NULL
+    ORDER BY next_fetch_at NULLS FIRST
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), updated_at = NOW(), lease_owner = NULL, lease_expires_at = NULL,
+    next_fetch_at = NOW() + make_interval(secs => sqlc.arg(next_fetch_seconds)::float8)
+WHERE feeds.id = sqlc.arg(id) AND feeds.lease_owner = sqlc.arg(lease_owner);
+
+-- name: RenewFeedLease :execrows
+UPDATE feeds
+SET lease_expires_at = NOW() + make_interval(secs => sqlc.arg(lease_seconds)::float8)
+WHERE feeds.id = sqlc.arg(id) AND feeds.lease_owner = sqlc.arg(lease_owner);
+
+-- name: ReturnFeedLease :exec
+UPDATE feeds
+SET lease_owner = NULL, lease_expires_at = NULL
+WHERE feeds.id = $1 AND feeds.lease_owner = $2;
+
+-- name: SetFeedFetchInterval :execrows
+UPDATE feeds
+SET fetch_interval_seconds = $2, next_fetch_at = NULL, updated_at = NOW()
+WHERE feeds.url = $1;
+
+-- name: UpdateFeedCacheHeaders :exec
+UPDATE feeds
+SET etag = $2, last_modified = $3
+WHERE feeds.id = $1;
+
+-- name: SetFeedTimezone :execrows
+UPDATE feeds
+SET timezone = $2, updated_at = NOW()
+WHERE feeds.url = $1;
+
+-- name: SetFeedSkips :exec
+UPDATE feeds
+SET skip_hours = $2, skip_days = $3
+WHERE feeds.id = $1;
+
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET consecutive_failures = 0, last_error = NULL, last_success_at = NOW()
+WHERE feeds.id = $1;
+
+-- name: RecordFeedFailure :one
+UPDATE feeds
+SET consecutive_failures = consecutive_failures + 1,
+    last_error = sqlc.arg(last_error),
+    disabled_at = CASE WHEN consecutive_failures + 1 >= sqlc.arg(max_failures)::integer THEN NOW() ELSE disabled_at END
+WHERE feeds.id = sqlc.arg(id)
+RETURNING consecutive_failures, disabled_at;
+
+-- name: GetBrokenFeeds :many
 SELECT * FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
-LIMIT 1;
\ No newline at end of file
+WHERE feeds.consecutive_failures > 0 OR feeds.disabled_at IS NOT NULL
+ORDER BY disabled_at NULLS LAST, consecutive_failures DESC;
+
+-- name: EnableFeed :execrows
+UPDATE feeds
+SET disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL, updated_at = NOW()
+WHERE feeds.url = $1;
+
+-- name: UpdateFeedUrl :exec
+UPDATE feeds
+SET url = $2, updated_at = NOW()
+WHERE feeds.id = $1;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE feeds.id = $1;
@@ -0,0 +1,4 @@
+-- name: GetPostRevisions :many
+SELECT * FROM post_revisions
+WHERE post_revisions.post_id = $1
+ORDER BY created_at DESC;
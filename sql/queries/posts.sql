@@ -1,16 +1,63 @@
--- name: CreatePost :one
-INSERT INTO posts ( title, url, description, published_at, feed_id) 
+-- name: UpsertPost :one
+WITH previous AS (
+    SELECT id, title, description, content, content_hash FROM posts
+    WHERE posts.feed_id = $6 AND posts.guid = $7
+), revision AS (
+    INSERT INTO post_revisions (post_id, title, description, content, content_hash)
+    SELECT id, title, description, content, content_hash FROM previous
+    WHERE previous.content_hash IS DISTINCT FROM $8
+)
+INSERT INTO posts ( title, url, description, content, published_at, feed_id, guid, content_hash, plain_text) 
 VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
-) RETURNING *;
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
+)
+ON CONFLICT (feed_id, guid) DO UPDATE
+SET title = EXCLUDED.title,
+    url = EXCLUDED.url,
+    description = EXCLUDED.description,
+    content = EXCLUDED.content,
+    plain_text = EXCLUDED.plain_text,
+    content_hash = EXCLUDED.content_hash,
+    updated_at = NOW()
+WHERE posts.content_hash IS DISTINCT FROM EXCLUDED.content_hash
+RETURNING *, (xmax = 0)::boolean AS inserted;
+
+-- name: ClaimLegacyPost :execrows
+UPDATE posts
+SET guid = $3, content_hash = $4, updated_at = NOW()
+WHERE posts.feed_id = $1 AND posts.url = $2 AND posts.guid IS NULL;
+
+-- name: PostWithContentHashExists :one
+SELECT EXISTS (
+    SELECT 1 FROM posts
+    WHERE posts.feed_id = $1 AND posts.content_hash = $2
+);
+
+-- name: GetPost :one
+SELECT * FROM posts
+WHERE posts.id = $1;
 
 -- name: GetPostsForUser :many
-SELECT * FROM posts p
+SELECT p.*, f.name AS feed_name FROM posts p
 JOIN feeds f ON p.feed_id = f.id
-WHERE f.user_id = $1
+JOIN feed_follows ff ON ff.feed_id = f.id
+WHERE ff.user_id = $1
 ORDER BY p.published_at DESC
-LIMIT $2;
\ No newline at end of file
+LIMIT $2;
+
+-- name: MovePosts :execrows
+UPDATE posts
+SET feed_id = sqlc.arg(to_feed_id), updated_at = NOW()
+WHERE posts.feed_id = sqlc.arg(from_feed_id)
+AND NOT EXISTS (
+    SELECT 1 FROM posts existing
+    WHERE existing.feed_id = sqlc.arg(to_feed_id) AND existing.guid = posts.guid
+);
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN content TEXT;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN content;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE enclosures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    mime_type TEXT,
+    length BIGINT,
+    duration_seconds INTEGER,
+    image_url TEXT,
+    UNIQUE(post_id, url)
+);
+
+-- +goose Down
+DROP TABLE enclosures;
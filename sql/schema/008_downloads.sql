@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE downloads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    enclosure_id UUID NOT NULL UNIQUE REFERENCES enclosures(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    bytes BIGINT NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+-- +goose Down
+DROP TABLE downloads;
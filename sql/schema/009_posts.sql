@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN guid TEXT;
+ALTER TABLE posts ADD COLUMN content_hash TEXT;
+ALTER TABLE posts DROP CONSTRAINT posts_url_key;
+ALTER TABLE posts ADD CONSTRAINT posts_feed_id_guid_key UNIQUE (feed_id, guid);
+
+-- +goose Down
+ALTER TABLE posts DROP CONSTRAINT posts_feed_id_guid_key;
+ALTER TABLE posts ADD CONSTRAINT posts_url_key UNIQUE (url);
+ALTER TABLE posts DROP COLUMN content_hash;
+ALTER TABLE posts DROP COLUMN guid;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE post_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    content TEXT,
+    content_hash TEXT
+);
+
+-- +goose Down
+DROP TABLE post_revisions;
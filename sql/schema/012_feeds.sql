@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN timezone TEXT;
+
+-- +goose Down
+ALTER TABLE feeds DROP COLUMN timezone;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE fetch_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    items_inserted INTEGER NOT NULL DEFAULT 0,
+    items_updated INTEGER NOT NULL DEFAULT 0,
+    items_duplicate INTEGER NOT NULL DEFAULT 0,
+    items_rejected INTEGER NOT NULL DEFAULT 0,
+    rejections JSONB NOT NULL DEFAULT '[]'
+);
+
+-- +goose Down
+DROP TABLE fetch_reports;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN plain_text TEXT;
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN plain_text;
@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE fetch_reports RENAME TO feed_fetches;
+ALTER TABLE feed_fetches ADD COLUMN started_at TIMESTAMP NOT NULL DEFAULT NOW();
+ALTER TABLE feed_fetches ADD COLUMN finished_at TIMESTAMP NOT NULL DEFAULT NOW();
+ALTER TABLE feed_fetches ADD COLUMN duration_ms INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feed_fetches ADD COLUMN http_status INTEGER;
+ALTER TABLE feed_fetches ADD COLUMN bytes BIGINT;
+ALTER TABLE feed_fetches ADD COLUMN not_modified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE feed_fetches ADD COLUMN error TEXT;
+-- rows logged before this migration keep their real dates
+UPDATE feed_fetches SET started_at = created_at, finished_at = created_at;
+CREATE INDEX feed_fetches_feed_id_started_at_idx ON feed_fetches (feed_id, started_at);
+
+-- +goose Down
+DROP INDEX feed_fetches_feed_id_started_at_idx;
+ALTER TABLE feed_fetches DROP COLUMN error;
+ALTER TABLE feed_fetches DROP COLUMN not_modified;
+ALTER TABLE feed_fetches DROP COLUMN bytes;
+ALTER TABLE feed_fetches DROP COLUMN http_status;
+ALTER TABLE feed_fetches DROP COLUMN duration_ms;
+ALTER TABLE feed_fetches DROP COLUMN finished_at;
+ALTER TABLE feed_fetches DROP COLUMN started_at;
+ALTER TABLE feed_fetches RENAME TO fetch_reports;
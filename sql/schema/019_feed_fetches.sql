@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE feed_fetches ADD COLUMN moved_to TEXT;
+
+-- +goose Down
+ALTER TABLE feed_fetches DROP COLUMN moved_to;
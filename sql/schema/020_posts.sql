@@ -0,0 +1,7 @@
+-- +goose Up
+CREATE INDEX posts_legacy_url_idx ON posts (feed_id, url) WHERE guid IS NULL;
+CREATE INDEX posts_feed_id_content_hash_idx ON posts (feed_id, content_hash);
+
+-- +goose Down
+DROP INDEX posts_feed_id_content_hash_idx;
+DROP INDEX posts_legacy_url_idx;